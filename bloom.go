@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"math"
+	"sync"
+
+	"github.com/gekatateam/go-generics-cache/internal/hash"
+)
+
+// bloomFilter is a thread safe Bloom filter for keys.
+//
+// It is reset when the number of added keys reaches the expected number of
+// items, so the false positive rate does not grow beyond the configured one.
+type bloomFilter[K comparable] struct {
+	mu       sync.Mutex
+	bits     []uint64
+	m        uint64 // number of bits
+	k        uint64 // number of hash functions
+	n        int    // number of added keys since the last reset
+	capacity int    // expected number of items
+}
+
+func newBloomFilter[K comparable](expectedItems int, fpRate float64) *bloomFilter[K] {
+	if expectedItems < 1 {
+		expectedItems = 1
+	}
+	if fpRate <= 0 || fpRate >= 1 {
+		fpRate = 0.01
+	}
+	// m = -n*ln(p) / ln(2)^2, k = m/n * ln(2)
+	m := uint64(math.Ceil(-float64(expectedItems) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(expectedItems) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter[K]{
+		bits:     make([]uint64, (m+63)/64),
+		m:        m,
+		k:        k,
+		capacity: expectedItems,
+	}
+}
+
+// add adds the key to the filter.
+func (b *bloomFilter[K]) add(key K) {
+	h1, h2 := b.hashes(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.n >= b.capacity {
+		b.resetLocked()
+	}
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		b.bits[pos/64] |= 1 << (pos % 64)
+	}
+	b.n++
+}
+
+// contains reports whether the key may have been added to the filter.
+// false means the key has definitely not been added.
+func (b *bloomFilter[K]) contains(key K) bool {
+	h1, h2 := b.hashes(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := uint64(0); i < b.k; i++ {
+		pos := (h1 + i*h2) % b.m
+		if b.bits[pos/64]&(1<<(pos%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reset clears the filter.
+func (b *bloomFilter[K]) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.resetLocked()
+}
+
+func (b *bloomFilter[K]) resetLocked() {
+	for i := range b.bits {
+		b.bits[i] = 0
+	}
+	b.n = 0
+}
+
+// hashes returns two hashes of the key for double hashing.
+func (b *bloomFilter[K]) hashes(key K) (uint64, uint64) {
+	h := hash.Key(key)
+	h1 := h & 0xffffffff
+	h2 := h>>32 | 1 // must be odd to visit distinct positions
+	return h1, h2
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestBloomFilter(t *testing.T) {
+	b := newBloomFilter[string](1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		b.add(strconv.Itoa(i))
+	}
+	for i := 0; i < 1000; i++ {
+		if !b.contains(strconv.Itoa(i)) {
+			t.Fatalf("want no false negative for %d", i)
+		}
+	}
+
+	falsePositives := 0
+	for i := 1000; i < 11000; i++ {
+		if b.contains(strconv.Itoa(i)) {
+			falsePositives++
+		}
+	}
+	if rate := float64(falsePositives) / 10000; rate > 0.03 {
+		t.Errorf("false positive rate is too high: %f", rate)
+	}
+
+	// reset automatically when reaching the expected number of items.
+	b.add("next")
+	if b.contains("0") || !b.contains("next") {
+		t.Error("want the filter to be reset before adding over capacity")
+	}
+
+	b.reset()
+	if b.contains("next") {
+		t.Error("want the filter to be empty after reset")
+	}
+}
+
+func TestMarkAbsent(t *testing.T) {
+	c := New(WithNegativeBloom[string, int](100, 0.01))
+	if c.MaybeAbsent("a") {
+		t.Fatal("want false before marking")
+	}
+	c.MarkAbsent("a")
+	if !c.MaybeAbsent("a") {
+		t.Fatal("want true after marking")
+	}
+	c.ResetAbsent()
+	if c.MaybeAbsent("a") {
+		t.Fatal("want false after reset")
+	}
+
+	// without the option
+	nc := New[string, int]()
+	nc.MarkAbsent("a")
+	if nc.MaybeAbsent("a") {
+		t.Fatal("want false without the filter")
+	}
+}
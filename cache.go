@@ -2,15 +2,25 @@ package cache
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/gekatateam/go-generics-cache/policy/arc"
 	"github.com/gekatateam/go-generics-cache/policy/clock"
 	"github.com/gekatateam/go-generics-cache/policy/fifo"
 	"github.com/gekatateam/go-generics-cache/policy/lfu"
 	"github.com/gekatateam/go-generics-cache/policy/lru"
+	"github.com/gekatateam/go-generics-cache/policy/lruk"
 	"github.com/gekatateam/go-generics-cache/policy/mru"
 	"github.com/gekatateam/go-generics-cache/policy/simple"
+	"github.com/gekatateam/go-generics-cache/policy/slru"
+	"github.com/gekatateam/go-generics-cache/policy/tinylfu"
+	"github.com/gekatateam/go-generics-cache/policy/twoqueue"
 )
 
 // Interface is a common-cache interface.
@@ -19,13 +29,66 @@ type Interface[K comparable, V any] interface {
 	Get(key K) (value V, ok bool)
 	// Set sets a value to the cache with key. replacing any existing value.
 	Set(key K, val V)
-	// Keys returns the keys of the cache. The order is relied on algorithms.
+	// Keys returns the keys of the cache. The order is relied on algorithms,
+	// and it is documented by each policy.
 	Keys() []K
 	// Delete deletes the item with provided key from the cache.
 	Delete(key K)
+	// Len returns the number of items in the cache.
+	Len() int
+}
+
+// EvictingInterface is an optional interface of the cache replacement policy.
+//
+// If the underlying cache implements it, Cache stores values by SetWithEvicted
+// instead of Set to know the item which has been evicted to make room for the
+// value. All of the policies in this module implement it.
+type EvictingInterface[K comparable, V any] interface {
+	Interface[K, V]
+	// SetWithEvicted sets a value to the cache with key like Set, and returns
+	// the evicted item if any.
+	SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool)
+}
+
+// PeekingInterface is an optional interface of the cache replacement policy.
+//
+// If the underlying cache implements it, Cache.Peek looks up items by Peek
+// instead of Get not to affect the eviction order. All of the policies in
+// this module implement it.
+type PeekingInterface[K comparable, V any] interface {
+	Interface[K, V]
+	// Peek looks up a key's value from the cache like Get, but does not
+	// update any state which is used to decide the item to be evicted.
+	Peek(key K) (value V, ok bool)
 }
 
 var (
+	_ = []PeekingInterface[struct{}, any]{
+		(*simple.Cache[struct{}, any])(nil),
+		(*lru.Cache[struct{}, any])(nil),
+		(*lfu.Cache[struct{}, any])(nil),
+		(*fifo.Cache[struct{}, any])(nil),
+		(*mru.Cache[struct{}, any])(nil),
+		(*clock.Cache[struct{}, any])(nil),
+		(*tinylfu.Cache[struct{}, any])(nil),
+		(*twoqueue.Cache[struct{}, any])(nil),
+		(*arc.Cache[struct{}, any])(nil),
+		(*lruk.Cache[struct{}, any])(nil),
+		(*slru.Cache[struct{}, any])(nil),
+	}
+	_ = []EvictingInterface[struct{}, any]{
+		(*simple.Cache[struct{}, any])(nil),
+		(*lru.Cache[struct{}, any])(nil),
+		(*lfu.Cache[struct{}, any])(nil),
+		(*fifo.Cache[struct{}, any])(nil),
+		(*mru.Cache[struct{}, any])(nil),
+		(*clock.Cache[struct{}, any])(nil),
+		(*tinylfu.Cache[struct{}, any])(nil),
+		(*twoqueue.Cache[struct{}, any])(nil),
+		(*arc.Cache[struct{}, any])(nil),
+		(*lruk.Cache[struct{}, any])(nil),
+		(*slru.Cache[struct{}, any])(nil),
+	}
 	_ = []Interface[struct{}, any]{
 		(*simple.Cache[struct{}, any])(nil),
 		(*lru.Cache[struct{}, any])(nil),
@@ -33,14 +96,54 @@ var (
 		(*fifo.Cache[struct{}, any])(nil),
 		(*mru.Cache[struct{}, any])(nil),
 		(*clock.Cache[struct{}, any])(nil),
+		(*tinylfu.Cache[struct{}, any])(nil),
+		(*twoqueue.Cache[struct{}, any])(nil),
+		(*arc.Cache[struct{}, any])(nil),
+		(*lruk.Cache[struct{}, any])(nil),
+		(*slru.Cache[struct{}, any])(nil),
 	}
+	_ lfu.Coster  = (*Item[struct{}, any])(nil)
+	_ lru.Expirer = (*Item[struct{}, any])(nil)
 )
 
 // Item is an item
 type Item[K comparable, V any] struct {
+	// hits is the number of accesses, which is counted with WithAccessCounting.
+	// This must be the first field to be 64-bit aligned for atomic operations.
+	hits uint64
+
 	Key        K
 	Value      V
 	Expiration time.Time
+
+	// epoch is the cache epoch which the item was stored in.
+	epoch uint64
+	// ref holds the value weakly instead of Value if it is not nil.
+	ref func() (V, bool)
+	// sliding is the duration which the expiration is renewed by on each Get.
+	sliding time.Duration
+	// size is the size of the item measured by the sizer of WithMaxBytes.
+	size int64
+	// cost is the cost to recompute the value, which is set by WithCost.
+	cost int64
+	// tags is the tags of the item, which are set by WithTags.
+	tags []string
+}
+
+// ExpiresAt returns the expiration time of the item, which is zero if the item
+// never expires. The LRU cache replacement policy can evict items which expire
+// first with lru.WithExpiryAwareEviction.
+func (item *Item[K, V]) ExpiresAt() time.Time {
+	return item.Expiration
+}
+
+// Cost returns the cost of the item which is set by WithCost, or 1 by default.
+// The LFU cache replacement policy retains costly items longer.
+func (item *Item[K, V]) Cost() int64 {
+	if item.cost < 1 {
+		return 1
+	}
+	return item.cost
 }
 
 // Expired returns true if the item has expired.
@@ -51,33 +154,181 @@ func (item *Item[K, V]) Expired() bool {
 	return nowFunc().After(item.Expiration)
 }
 
+// load returns the value of the item. ok is false if the value has been
+// held weakly and collected by the GC.
+func (item *Item[K, V]) load() (V, bool) {
+	if item.ref != nil {
+		return item.ref()
+	}
+	return item.Value, true
+}
+
+// store replaces the value of the item, keeping it weakly if it was.
+func (item *Item[K, V]) store(val V, weakRef func(V) func() (V, bool)) {
+	if weakRef != nil {
+		var zero V
+		item.Value = zero
+		item.ref = weakRef(val)
+		return
+	}
+	item.Value = val
+}
+
 var nowFunc = time.Now
 
+// jitterFunc returns a random number in [0, n) for WithExpirationJitter
+// unless the cache is created with WithJitterSource.
+var jitterFunc = rand.Int63n
+
+// maxJitter is the largest jitter of WithExpirationJitter, so that the range
+// of the offset fits in int64.
+const maxJitter = time.Duration((math.MaxInt64 - 1) / 2)
+
 // ItemOption is an option for cache item.
 type ItemOption func(*itemOptions)
 
 type itemOptions struct {
-	expiration time.Time // default none
+	expiration   time.Time     // default none
+	ttl          time.Duration // resolved to the expiration at Set if hasTTL
+	hasTTL       bool
+	noExpiration bool          // opts out of the default expiration of the cache
+	sliding      time.Duration // renews the expiration on each Get if not zero
+	jitter       time.Duration // spreads the expiration by up to ± jitter
+	offset       time.Duration // random offset within jitter resolved at Set
+	epoch        *uint64       // default current epoch of the cache
+	cost         int64         // default 1
+	tags         []string      // groups the item for InvalidateTag
 }
 
 // WithExpiration is an option to set expiration time for any items.
 // If the expiration is zero or negative value, it treats as w/o expiration.
+//
+// The expiration is relative to the time when the item is set, so the option
+// can be built once and reused for many Set calls.
 func WithExpiration(exp time.Duration) ItemOption {
 	return func(o *itemOptions) {
-		o.expiration = nowFunc().Add(exp)
+		o.expiration = time.Time{}
+		o.ttl, o.hasTTL = exp, true
+		o.noExpiration = false
+		o.sliding = 0
+		o.jitter = 0
+	}
+}
+
+// WithExpirationJitter is an option to set expiration time like WithExpiration,
+// spreading it randomly within base ± jitter. This prevents many items which
+// are set at once from expiring at the same time and being reloaded at once.
+// The random offset is chosen on each Set, so the option can be reused.
+//
+// If base is zero or negative value, the default expiration of the cache which
+// is set by WithDefaultExpiration is spread instead, and the item is stored
+// w/o expiration if there is no default. jitter should be less than the
+// expiration, otherwise the item may be expired as soon as it is set. jitter
+// is capped at about 146 years.
+//
+// The offset is chosen by the global source of math/rand by default, and by
+// the source of WithJitterSource if it is specified.
+func WithExpirationJitter(base, jitter time.Duration) ItemOption {
+	return func(o *itemOptions) {
+		if base > 0 {
+			WithExpiration(base)(o)
+		} else {
+			o.expiration = time.Time{}
+			o.ttl, o.hasTTL = 0, false
+			o.noExpiration = false
+			o.sliding = 0
+		}
+		o.jitter = jitter
+	}
+}
+
+// WithSlidingExpiration is an option to set expiration time for any items,
+// which is renewed to now + exp on each successful Get.
+//
+// Get of the sliding item takes the write lock in addition to the read lock
+// to renew the expiration, so it is more expensive than Get of other items.
+// If the expiration is zero or negative value, it treats as w/o expiration.
+func WithSlidingExpiration(exp time.Duration) ItemOption {
+	return func(o *itemOptions) {
+		if exp <= 0 {
+			WithNoExpiration()(o)
+			return
+		}
+		o.expiration = time.Time{}
+		o.ttl, o.hasTTL = exp, true
+		o.noExpiration = false
+		o.sliding = exp
+		o.jitter = 0
+	}
+}
+
+// WithNoExpiration is an option to store an item w/o expiration even if the
+// cache is created with WithDefaultExpiration.
+//
+// The expiration options WithExpiration, WithExpirationJitter,
+// WithSlidingExpiration and WithNoExpiration override each other, and the
+// last one wins.
+func WithNoExpiration() ItemOption {
+	return func(o *itemOptions) {
+		o.expiration = time.Time{}
+		o.ttl, o.hasTTL = 0, false
+		o.noExpiration = true
+		o.sliding = 0
+		o.jitter = 0
+	}
+}
+
+// WithCost is an option to set the cost to recompute the value of the item.
+//
+// The LFU cache replacement policy evicts the item with the lowest score of
+// the access frequency * cost, so a costly item is retained longer than cheap
+// ones at the same frequency. Other policies ignore it. Default is 1.
+func WithCost(cost int64) ItemOption {
+	return func(o *itemOptions) {
+		o.cost = cost
+	}
+}
+
+// WithEpoch is an option to stamp an item with the cache epoch which was
+// observed before the value was produced (see Cache.Epoch).
+//
+// If the epoch has been advanced by BumpEpoch in the meantime, the item is
+// treated as stale and is not stored. This prevents a loader which started
+// before the bump from repopulating the cache with old data.
+func WithEpoch(epoch uint64) ItemOption {
+	return func(o *itemOptions) {
+		o.epoch = &epoch
 	}
 }
 
-// newItem creates a new item with specified any options.
-func newItem[K comparable, V any](key K, val V, opts ...ItemOption) *Item[K, V] {
+// newItemOptions applies specified any options, and resolves the expiration
+// relative to now. random returns a random number in [0, n) for the jitter.
+func newItemOptions(random func(n int64) int64, opts ...ItemOption) *itemOptions {
 	o := new(itemOptions)
 	for _, optFunc := range opts {
 		optFunc(o)
 	}
+	if o.jitter > maxJitter {
+		o.jitter = maxJitter
+	}
+	if o.jitter > 0 {
+		o.offset = time.Duration(random(2*int64(o.jitter)+1)) - o.jitter
+	}
+	if o.hasTTL {
+		o.expiration = nowFunc().Add(o.ttl + o.offset)
+	}
+	return o
+}
+
+// newItem creates a new item with the applied options.
+func newItem[K comparable, V any](key K, val V, o *itemOptions) *Item[K, V] {
 	return &Item[K, V]{
 		Key:        key,
 		Value:      val,
 		Expiration: o.expiration,
+		sliding:    o.sliding,
+		cost:       o.cost,
+		tags:       o.tags,
 	}
 }
 
@@ -88,14 +339,108 @@ type Cache[K comparable, V any] struct {
 	// mu is used to do lock in some method process.
 	mu      sync.RWMutex
 	janitor *janitor
+	// pool is the shared janitor pool which the cache is registered with if any.
+	pool *JanitorPool
+	// closeOnce makes Close idempotent.
+	closeOnce sync.Once
+	// evictOnClose reports whether Close removes the remaining items.
+	evictOnClose bool
+	// epoch is advanced by BumpEpoch. Items stored in older epochs are treated as absent.
+	epoch uint64
+	// generation is advanced by each change of the items.
+	generation uint64
+	// sweep is the rest of keys to be examined in the current pass of DeleteExpiredN.
+	sweep []K
+	// frozen makes the items immutable while it is true. See Freeze.
+	frozen bool
+	// expiries indexes the items with an expiration by the expiration, so
+	// DeleteExpired examines only the expired items unless other items can
+	// be expired.
+	expiries expiryIndex[K]
+	// tags indexes the keys of the items by their tags of WithTags.
+	tags map[string]map[K]struct{}
+	// staleEpoch is true after BumpEpoch until the next DeleteExpired pass.
+	staleEpoch bool
+	// absent is a Bloom filter of keys known to be absent from the backend.
+	absent *bloomFilter[K]
+	// admission is a Bloom filter of keys which have been set once, and only
+	// the keys in it are stored if it is not nil.
+	admission *bloomFilter[K]
+	// weakRef makes a weak reference to the value if it is not nil.
+	weakRef func(V) func() (V, bool)
+	// trace records the access trace if it is not nil.
+	trace *traceRecorder[K]
+	// accessCounting reports whether accesses of each item are counted.
+	accessCounting bool
+	// overflow is the action when the cache is full.
+	overflow        OverflowPolicy
+	overflowTimeout time.Duration
+	// freed is closed when an item is deleted, to wake up Set calls blocked by OverflowBlock.
+	freed chan struct{}
+	// onEvicted is called for each removed item after the lock is released.
+	onEvicted func(key K, val V)
+	// evicted is the removed items which are waiting for onEvicted.
+	evicted []*Item[K, V]
+	// onExpired is called for each expired item deleted by DeleteExpired after the lock is released.
+	onExpired func(key K, val V)
+	// expiredItems is the expired items which are waiting for onExpired.
+	expiredItems []*Item[K, V]
+	// stats collects statistics if it is not nil.
+	stats *statsCounter
+	// subscribers receive events published by the mutations of the cache.
+	subscribers   []chan Event[K, V]
+	droppedEvents uint64
+	// loads deduplicates concurrent loads of GetOrLoad.
+	loads group[K, V]
+	// refreshes is the keys which are being refreshed by GetOrRefresh.
+	refreshes keySet[K]
+	// keyLocks is the locks of keys which are held by Update.
+	keyLocks keyMutex[K]
+	// negatives is the expirations of the cached absences of keys by WithNegativeTTL.
+	negatives   map[K]time.Time
+	negativeTTL time.Duration
+	// defaultExpiration is applied to items which are set w/o expiration.
+	defaultExpiration time.Duration
+	// sizer measures the size of items if it is not nil, and the cache evicts
+	// items while the total size of them exceeds maxBytes.
+	sizer    func(K, V) int64
+	maxBytes int64
+	bytes    int64
+	// jitter chooses the offsets of WithExpirationJitter if it is not nil.
+	jitter *rand.Rand
 }
 
 // Option is an option for cache.
 type Option[K comparable, V any] func(*options[K, V])
 
 type options[K comparable, V any] struct {
-	cache           Interface[K, *Item[K, V]]
-	janitorInterval time.Duration
+	cache             Interface[K, *Item[K, V]]
+	janitorInterval   time.Duration
+	janitorPool       *JanitorPool
+	incrementCallback func(key K, delta, newValue V)
+	absent            *bloomFilter[K]
+	admission         *bloomFilter[K]
+	weakRef           func(V) func() (V, bool)
+	trace             *traceRecorder[K]
+	accessCounting    bool
+	overflow          OverflowPolicy
+	overflowTimeout   time.Duration
+	onEvicted         func(key K, val V)
+	onExpired         func(key K, val V)
+	evictOnClose      bool
+	stats             bool
+	hasher            func(K) uint64
+	defaultExpiration time.Duration
+	negativeTTL       time.Duration
+	sizer             func(K, V) int64
+	maxBytes          int64
+	// policies counts the options which specify the cache replacement policy.
+	policies int
+	// custom reports whether the policy is given by WithPolicy.
+	custom bool
+	jitter *rand.Rand
+	// errs is the invalid arguments of the options, which are reported by NewChecked.
+	errs []error
 }
 
 func newOptions[K comparable, V any]() *options[K, V] {
@@ -105,43 +450,150 @@ func newOptions[K comparable, V any]() *options[K, V] {
 	}
 }
 
+// setPolicy sets the cache replacement policy.
+func (o *options[K, V]) setPolicy(p Interface[K, *Item[K, V]]) {
+	o.cache = p
+	o.policies++
+}
+
+// invalid records an invalid argument of an option.
+func (o *options[K, V]) invalid(format string, args ...any) {
+	o.errs = append(o.errs, fmt.Errorf("%w: "+format, append([]any{ErrInvalidOption}, args...)...))
+}
+
+// validate returns the first invalid or conflicting option if any.
+func (o *options[K, V]) validate() error {
+	if len(o.errs) > 0 {
+		return o.errs[0]
+	}
+	if o.policies > 1 {
+		return fmt.Errorf("%w: %d cache replacement policies are specified", ErrInvalidOption, o.policies)
+	}
+	if bounded, ok := o.cache.(interface{ Cap() int }); ok && bounded.Cap() < 0 {
+		return fmt.Errorf("%w: negative capacity %d", ErrInvalidOption, bounded.Cap())
+	}
+	return nil
+}
+
+// AsSimple is an option to make a new Cache as simple algorithm.
+//
+// This is the default, it is only needed to pass options to the simple cache.
+func AsSimple[K comparable, V any](opts ...simple.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.setPolicy(simple.NewCache[K, *Item[K, V]](opts...))
+	}
+}
+
 // AsLRU is an option to make a new Cache as LRU algorithm.
 func AsLRU[K comparable, V any](opts ...lru.Option) Option[K, V] {
 	return func(o *options[K, V]) {
-		o.cache = lru.NewCache[K, *Item[K, V]](opts...)
+		o.setPolicy(lru.NewCache[K, *Item[K, V]](opts...))
+	}
+}
+
+// AsLRUK is an option to make a new Cache as LRU-K algorithm, which tracks
+// the last k access times of each item.
+func AsLRUK[K comparable, V any](k int, opts ...lruk.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		if k < 1 {
+			o.invalid("AsLRUK: k must be at least 1, got %d", k)
+		}
+		o.setPolicy(lruk.NewCache[K, *Item[K, V]](k, opts...))
+	}
+}
+
+// AsSLRU is an option to make a new Cache as SLRU algorithm, which has the
+// probationary segment and the protected segment of the given sizes.
+func AsSLRU[K comparable, V any](probationSize, protectedSize int) Option[K, V] {
+	return func(o *options[K, V]) {
+		if probationSize < 1 || protectedSize < 0 {
+			o.invalid("AsSLRU: invalid segment sizes %d and %d", probationSize, protectedSize)
+		}
+		o.setPolicy(slru.NewCache[K, *Item[K, V]](probationSize, protectedSize))
 	}
 }
 
 // AsLFU is an option to make a new Cache as LFU algorithm.
 func AsLFU[K comparable, V any](opts ...lfu.Option) Option[K, V] {
 	return func(o *options[K, V]) {
-		o.cache = lfu.NewCache[K, *Item[K, V]](opts...)
+		o.setPolicy(lfu.NewCache[K, *Item[K, V]](opts...))
+	}
+}
+
+// AsTinyLFU is an option to make a new Cache as W-TinyLFU algorithm.
+func AsTinyLFU[K comparable, V any](opts ...tinylfu.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.setPolicy(tinylfu.NewCache[K, *Item[K, V]](opts...))
+	}
+}
+
+// As2Q is an option to make a new Cache as 2Q algorithm.
+func As2Q[K comparable, V any](opts ...twoqueue.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.setPolicy(twoqueue.NewCache[K, *Item[K, V]](opts...))
+	}
+}
+
+// AsARC is an option to make a new Cache as ARC algorithm.
+func AsARC[K comparable, V any](opts ...arc.Option) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.setPolicy(arc.NewCache[K, *Item[K, V]](opts...))
 	}
 }
 
 // AsFIFO is an option to make a new Cache as FIFO algorithm.
 func AsFIFO[K comparable, V any](opts ...fifo.Option) Option[K, V] {
 	return func(o *options[K, V]) {
-		o.cache = fifo.NewCache[K, *Item[K, V]](opts...)
+		o.setPolicy(fifo.NewCache[K, *Item[K, V]](opts...))
 	}
 }
 
 // AsMRU is an option to make a new Cache as MRU algorithm.
 func AsMRU[K comparable, V any](opts ...mru.Option) Option[K, V] {
 	return func(o *options[K, V]) {
-		o.cache = mru.NewCache[K, *Item[K, V]](opts...)
+		o.setPolicy(mru.NewCache[K, *Item[K, V]](opts...))
 	}
 }
 
 // AsClock is an option to make a new Cache as clock algorithm.
 func AsClock[K comparable, V any](opts ...clock.Option) Option[K, V] {
 	return func(o *options[K, V]) {
-		o.cache = clock.NewCache[K, *Item[K, V]](opts...)
+		o.setPolicy(clock.NewCache[K, *Item[K, V]](opts...))
+	}
+}
+
+// WithPolicy is an option to make a new Cache with a custom cache replacement
+// policy p, which must be empty and must not be used by anything else.
+//
+// Cache serializes the calls to p by its own lock, so p does not need to be
+// thread safe, except that Get, Peek and Keys may be called concurrently with
+// each other under the read lock. Values of p are the items of Cache, and p
+// must not modify them.
+//
+// p is responsible for limiting its size. If p evicts an item to make room for
+// a new one, it should implement EvictingInterface to report it, otherwise the
+// eviction callback, the statistics and the size limit of WithMaxBytes do not
+// know the eviction. p may also implement PeekingInterface, and the optional
+// methods of the policies in this module, such as Evict, Resize, Clear and
+// KeysInEvictionOrder, to support the methods of Cache which depend on them.
+func WithPolicy[K comparable, V any](p Interface[K, *Item[K, V]]) Option[K, V] {
+	return func(o *options[K, V]) {
+		if p == nil {
+			o.invalid("WithPolicy: nil policy")
+			return
+		}
+		o.setPolicy(p)
+		o.custom = true
 	}
 }
 
 // WithJanitorInterval is an option to specify how often cache should delete expired items.
 //
+// Zero or negative value disables the janitor, so that no goroutine is started.
+// Expired items are still treated as absent by Get, but they are kept in the
+// cache until they are overwritten, evicted or deleted by Delete or
+// DeleteExpired.
+//
 // Default is 1 minute.
 func WithJanitorInterval[K comparable, V any](d time.Duration) Option[K, V] {
 	return func(o *options[K, V]) {
@@ -149,10 +601,201 @@ func WithJanitorInterval[K comparable, V any](d time.Duration) Option[K, V] {
 	}
 }
 
+// WithDefaultExpiration is an option to specify the expiration of items which
+// are set w/o WithExpiration. WithNoExpiration opts a specific item out of it.
+//
+// Default is none, zero or negative value also means w/o expiration.
+func WithDefaultExpiration[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.defaultExpiration = d
+	}
+}
+
+// WithJitterSource is an option to choose the random offsets of
+// WithExpirationJitter by src instead of the global source of math/rand, so
+// that the expirations are reproducible with a seeded source such as
+// rand.NewSource(seed).
+//
+// src does not need to be safe for concurrent use since it is guarded by a
+// mutex, which also lets the shards of NewSharded share it. It must not be
+// used by anything else.
+func WithJitterSource[K comparable, V any](src rand.Source) Option[K, V] {
+	if src != nil {
+		src = &lockedSource{src: src}
+	}
+	return func(o *options[K, V]) {
+		if src == nil {
+			o.invalid("WithJitterSource: nil source")
+			return
+		}
+		o.jitter = rand.New(src)
+	}
+}
+
+// lockedSource is a rand.Source which is safe for concurrent use.
+type lockedSource struct {
+	mu  sync.Mutex
+	src rand.Source
+}
+
+func (s *lockedSource) Int63() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.src.Int63()
+}
+
+func (s *lockedSource) Seed(seed int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.src.Seed(seed)
+}
+
+// WithSharedJanitor is an option to delete expired items by the shared janitor
+// pool instead of a dedicated janitor goroutine of the cache.
+//
+// The cache is unregistered from the pool when the context passed to
+// NewContext is cancelled. WithJanitorInterval is ignored with this option.
+func WithSharedJanitor[K comparable, V any](pool *JanitorPool) Option[K, V] {
+	return func(o *options[K, V]) {
+		if pool == nil {
+			o.invalid("WithSharedJanitor: nil pool")
+		}
+		o.janitorPool = pool
+	}
+}
+
+// WithNegativeBloom is an option to keep a Bloom filter of keys which are known
+// to be absent from the backend fronted by the cache (see Cache.MarkAbsent).
+//
+// The filter is sized for expectedItems keys at the false positive rate fpRate,
+// and is reset automatically once expectedItems keys have been marked, so the
+// false positive rate does not grow beyond fpRate.
+func WithNegativeBloom[K comparable, V any](expectedItems int, fpRate float64) Option[K, V] {
+	return func(o *options[K, V]) {
+		if expectedItems < 1 || fpRate <= 0 || fpRate >= 1 {
+			o.invalid("WithNegativeBloom: invalid filter size %d or false positive rate %v", expectedItems, fpRate)
+		}
+		o.absent = newBloomFilter[K](expectedItems, fpRate)
+	}
+}
+
+// WithAdmissionFilter is an option to keep one-hit-wonders out of the cache.
+// A new key is stored only on its second Set, and the first Set is just
+// recorded in a Bloom filter and the value is dropped. Replacing an existing
+// item is always admitted.
+//
+// TrySet returns ErrNotAdmitted for a dropped value, and GetOrSet returns the
+// zero value and false. GetOrLoad returns the loaded value without an error
+// even if it is dropped.
+//
+// The filter is sized for estimatedKeys keys at the false positive rate
+// fpRate, and is reset automatically once estimatedKeys keys have been
+// recorded, so a key must be set twice within that window. It can also be
+// reset by ResetAdmission.
+func WithAdmissionFilter[K comparable, V any](estimatedKeys int, fpRate float64) Option[K, V] {
+	return func(o *options[K, V]) {
+		if estimatedKeys < 1 || fpRate <= 0 || fpRate >= 1 {
+			o.invalid("WithAdmissionFilter: invalid filter size %d or false positive rate %v", estimatedKeys, fpRate)
+		}
+		o.admission = newBloomFilter[K](estimatedKeys, fpRate)
+	}
+}
+
+// WithAccessCounting is an option to count how many times each item has been
+// read by Get since it was stored, regardless of the cache replacement policy.
+// The count can be read by GetWithCount.
+func WithAccessCounting[K comparable, V any]() Option[K, V] {
+	return func(o *options[K, V]) {
+		o.accessCounting = true
+	}
+}
+
+// ErrCacheFull is returned by TrySet when the value is not stored because the cache is full.
+var ErrCacheFull = errors.New("cache: cache is full")
+
+// ErrNotAdmitted is returned by TrySet when the value is not stored because
+// the key is set for the first time with WithAdmissionFilter.
+var ErrNotAdmitted = errors.New("cache: key is not admitted")
+
+// OverflowPolicy is an action when a new item is stored to the full cache.
+type OverflowPolicy int
+
+const (
+	// OverflowEvict evicts an item by the cache replacement policy. This is the default.
+	OverflowEvict OverflowPolicy = iota
+	// OverflowReject rejects the new item and keeps existing items.
+	OverflowReject
+	// OverflowBlock waits until an item is deleted by Delete or the janitor.
+	OverflowBlock
+)
+
+// WithOverflowPolicy is an option to specify the action when a new item is
+// stored to the full cache. It has effect only with bounded cache replacement
+// policies, the simple cache is never full. Replacing an existing item never overflows.
+//
+// Default is OverflowEvict.
+func WithOverflowPolicy[K comparable, V any](p OverflowPolicy) Option[K, V] {
+	return func(o *options[K, V]) {
+		if p < OverflowEvict || p > OverflowBlock {
+			o.invalid("WithOverflowPolicy: unknown policy %d", p)
+		}
+		o.overflow = p
+	}
+}
+
+// WithOverflowTimeout is an option to specify how long Set waits for space with OverflowBlock.
+//
+// Default is 0, which means waiting forever.
+func WithOverflowTimeout[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		if d < 0 {
+			o.invalid("WithOverflowTimeout: negative timeout %v", d)
+		}
+		o.overflowTimeout = d
+	}
+}
+
+// WithEvictionCallback is an option to be notified when an item is removed from
+// the cache by Delete, the janitor, Flush, Clear, Close with WithEvictionOnClose
+// or eviction of the replacement policy. It is not called when an item is
+// replaced by Set. So it can be used to release resources held by values, such
+// as closing connections deterministically w/o relying on GC.
+//
+// fn is called synchronously by the goroutine which removed the item, after the
+// item has been removed and the lock of the cache is released, and before the
+// method which removed it returns. So it may call back into the cache, and the
+// item is not found there. Items removed at once are passed in the order of
+// removal.
+func WithEvictionCallback[K comparable, V any](fn func(key K, val V)) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.onEvicted = fn
+	}
+}
+
+// WithExpiredCallback is an option to be notified when an expired item is
+// deleted by DeleteExpired, which is called by the janitor. It is not called
+// for items which are removed by Delete or eviction of the replacement policy.
+//
+// fn is called after the lock of the cache is released like the eviction
+// callback, so it may re-Set the key.
+func WithExpiredCallback[K comparable, V any](fn func(key K, val V)) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.onExpired = fn
+	}
+}
+
+// WithEvictionOnClose is an option to remove the remaining items by Close, so
+// that the eviction callback is called for each of them.
+func WithEvictionOnClose[K comparable, V any]() Option[K, V] {
+	return func(o *options[K, V]) {
+		o.evictOnClose = true
+	}
+}
+
 // New creates a new thread safe Cache.
-// The janitor will not be stopped which is created by this function. If you
-// want to stop the janitor gracefully, You should use the `NewContext` function
-// instead of this.
+// The janitor which is created by this function is stopped only by Close. If
+// you want to stop the janitor by a context, You should use the `NewContext`
+// function instead of this.
 //
 // There are several Cache replacement policies available with you specified any options.
 func New[K comparable, V any](opts ...Option[K, V]) *Cache[K, V] {
@@ -168,141 +811,1610 @@ func NewContext[K comparable, V any](ctx context.Context, opts ...Option[K, V])
 	for _, optFunc := range opts {
 		optFunc(o)
 	}
+	return newCache(ctx, o)
+}
+
+// ErrInvalidOption is returned by NewChecked when the options are invalid or conflicting.
+var ErrInvalidOption = errors.New("cache: invalid option")
+
+// NewChecked creates a new thread safe Cache like New, but it returns an error
+// wrapping ErrInvalidOption instead of silently correcting or ignoring invalid
+// options, such as more than one option of the cache replacement policy,
+// negative capacity or WithMaxBytes w/o a sizer.
+func NewChecked[K comparable, V any](opts ...Option[K, V]) (_ *Cache[K, V], err error) {
+	o := newOptions[K, V]()
+	// the policies panic on some invalid arguments such as negative capacity.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrInvalidOption, r)
+		}
+	}()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	return newCache(context.Background(), o), nil
+}
+
+// newCache creates a new thread safe Cache with the applied options.
+func newCache[K comparable, V any](ctx context.Context, o *options[K, V]) *Cache[K, V] {
 	cache := &Cache[K, V]{
-		cache:   o.cache,
-		janitor: newJanitor(ctx, o.janitorInterval),
+		cache:             o.cache,
+		absent:            o.absent,
+		admission:         o.admission,
+		weakRef:           o.weakRef,
+		trace:             o.trace,
+		accessCounting:    o.accessCounting,
+		overflow:          o.overflow,
+		overflowTimeout:   o.overflowTimeout,
+		onEvicted:         o.onEvicted,
+		onExpired:         o.onExpired,
+		evictOnClose:      o.evictOnClose,
+		defaultExpiration: o.defaultExpiration,
+		negativeTTL:       o.negativeTTL,
+		sizer:             o.sizer,
+		maxBytes:          o.maxBytes,
+		jitter:            o.jitter,
+	}
+	if o.stats {
+		cache.stats = new(statsCounter)
 	}
+	if o.janitorPool != nil {
+		pool := o.janitorPool
+		cache.pool = pool
+		pool.register(cache, cache.DeleteExpired)
+		if done := ctx.Done(); done != nil {
+			go func() {
+				<-done
+				pool.unregister(cache)
+			}()
+		}
+		return cache
+	}
+	if o.janitorInterval <= 0 {
+		return cache
+	}
+	cache.janitor = newJanitor(ctx, o.janitorInterval)
 	cache.janitor.run(cache.DeleteExpired)
 	return cache
 }
 
-// Get looks up a key's value from the cache.
-func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+// PolicyName returns a stable identifier of the cache replacement policy which
+// is used by the cache, such as "simple", "lru", "lfu", "fifo", "mru", "clock",
+// "tinylfu", "2q", "arc", "lruk" or "slru". It is "unknown" for a custom
+// policy of WithPolicy.
+func (c *Cache[K, V]) PolicyName() string {
+	switch c.cache.(type) {
+	case *simple.Cache[K, *Item[K, V]]:
+		return "simple"
+	case *lru.Cache[K, *Item[K, V]]:
+		return "lru"
+	case *lfu.Cache[K, *Item[K, V]]:
+		return "lfu"
+	case *fifo.Cache[K, *Item[K, V]]:
+		return "fifo"
+	case *mru.Cache[K, *Item[K, V]]:
+		return "mru"
+	case *clock.Cache[K, *Item[K, V]]:
+		return "clock"
+	case *tinylfu.Cache[K, *Item[K, V]]:
+		return "tinylfu"
+	case *twoqueue.Cache[K, *Item[K, V]]:
+		return "2q"
+	case *arc.Cache[K, *Item[K, V]]:
+		return "arc"
+	case *lruk.Cache[K, *Item[K, V]]:
+		return "lruk"
+	case *slru.Cache[K, *Item[K, V]]:
+		return "slru"
+	}
+	return "unknown"
+}
+
+// FrequencyBuckets returns the distribution of access frequencies of the LFU
+// cache, mapping each frequency to the number of items at that frequency.
+// ok is false if the cache replacement policy does not track frequencies.
+func (c *Cache[K, V]) FrequencyBuckets() (buckets map[uint]int, ok bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	item, ok := c.cache.Get(key)
-
+	f, ok := c.cache.(interface{ FrequencyBuckets() map[uint]int })
 	if !ok {
-		return
-	}
-
-	// Returns nil if the item has been expired.
-	// Do not delete here and leave it to an external process such as Janitor.
-	if item.Expired() {
-		return value, false
+		return nil, false
 	}
-
-	return item.Value, true
+	return f.FrequencyBuckets(), true
 }
 
-// DeleteExpired all expired items from the cache.
-func (c *Cache[K, V]) DeleteExpired() {
-	c.mu.Lock()
-	keys := c.cache.Keys()
-	c.mu.Unlock()
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	value, _, ok = c.GetWithCount(key)
+	return
+}
 
-	for _, key := range keys {
-		c.mu.Lock()
-		// if is expired, delete it and return nil instead
-		item, ok := c.cache.Get(key)
-		if ok && item.Expired() {
-			c.cache.Delete(key)
-		}
-		c.mu.Unlock()
-	}
+// GetWithCount looks up a key's value from the cache like Get, and also returns
+// how many times the item has been read since it was stored, including this call.
+//
+// The count is always 0 unless the cache is created with WithAccessCounting.
+func (c *Cache[K, V]) GetWithCount(key K) (value V, count uint64, ok bool) {
+	value, count, _, ok = c.getWithExpiration(key)
+	return
 }
 
-// Set sets a value to the cache with key. replacing any existing value.
-func (c *Cache[K, V]) Set(key K, val V, opts ...ItemOption) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	item := newItem(key, val, opts...)
-	c.cache.Set(key, item)
+// GetWithExpiration looks up a key's value from the cache like Get, and also
+// returns the absolute expiration of the item, which is the zero time if the
+// item never expires. ok is false if the key is not found or has been expired.
+func (c *Cache[K, V]) GetWithExpiration(key K) (value V, expiration time.Time, ok bool) {
+	value, _, expiration, ok = c.getWithExpiration(key)
+	return
 }
 
-// Keys returns the keys of the cache. the order is relied on algorithms.
-func (c *Cache[K, V]) Keys() []K {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.cache.Keys()
+// getWithExpiration looks up a key's value, the access count and the
+// expiration of the item, and renews the expiration of the sliding item.
+func (c *Cache[K, V]) getWithExpiration(key K) (value V, count uint64, expiration time.Time, ok bool) {
+	if c.trace != nil {
+		c.trace.record(traceGet, key)
+	}
+	item, value, count, expiration, ok := c.getWithCount(key)
+	if ok && item.sliding > 0 {
+		expiration = c.slide(item)
+	}
+	return value, count, expiration, ok
 }
 
-func (c *Cache[K, V]) List() map[K]V {
+// getWithCount looks up the item of key and its expiration under the read lock.
+func (c *Cache[K, V]) getWithCount(key K) (item *Item[K, V], value V, count uint64, expiration time.Time, ok bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	keys := c.cache.Keys()
-	items := make(map[K]V, len(keys))
-	for _, v := range keys {
-		item, ok := c.cache.Get(v)
-		if ok {
-			items[v] = item.Value
-		}
+	// Returns nil if the item has been expired.
+	// Do not delete here and leave it to an external process such as Janitor.
+	item, value, ok = c.get(key)
+	if !ok {
+		c.stats.miss()
+		return
 	}
-
-	return items
+	c.stats.hit()
+	if c.accessCounting {
+		// Get holds only the read lock, so the count must be updated atomically.
+		count = atomic.AddUint64(&item.hits, 1)
+	}
+	return item, value, count, item.Expiration, ok
 }
 
-func (c *Cache[K, V]) Flush() {
+// slide renews the expiration of the sliding item under the write lock, and
+// returns the expiration.
+func (c *Cache[K, V]) slide(item *Item[K, V]) time.Time {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if !c.expired(item) {
+		item.Expiration = nowFunc().Add(item.sliding)
+		c.track(item)
+	}
+	return item.Expiration
+}
+
+// MustGet looks up a key's value from the cache like Get, but panics if
+// the key is not found or the item has been expired.
+//
+// It is intended for code paths where a missing key is a programming error.
+// A stored zero value is returned as is and does not cause a panic.
+func (c *Cache[K, V]) MustGet(key K) V {
+	value, ok := c.Get(key)
+	if !ok {
+		panic(fmt.Sprintf("cache: key %v is not found", key))
+	}
+	return value
+}
+
+// GetAndTouchMany looks up the values of keys from the cache and extends the
+// expiration of each found item to exp from now, under a single lock.
+//
+// Keys which are not found or have been expired are omitted from the returned map.
+func (c *Cache[K, V]) GetAndTouchMany(keys []K, exp time.Duration) map[K]V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items := make(map[K]V, len(keys))
+	for _, key := range keys {
+		item, val, ok := c.get(key)
+		if !ok {
+			continue
+		}
+		if !c.frozen {
+			c.untrack(item)
+			item.Expiration = nowFunc().Add(exp)
+			c.track(item)
+		}
+		items[key] = val
+	}
+	return items
+}
+
+// Peek returns a copy of the item of key including its expiration, without
+// affecting the eviction order of the cache replacement policy or statistics.
+//
+// Returns false if the key is not found or has been expired.
+func (c *Cache[K, V]) Peek(key K) (Item[K, V], bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, val, ok := c.peekItem(key)
+	if !ok {
+		return Item[K, V]{}, false
+	}
+	return Item[K, V]{
+		Key:        item.Key,
+		Value:      val,
+		Expiration: item.Expiration,
+	}, true
+}
+
+// peekItem looks up the live item of key like get, but by Peek of the
+// underlying cache if it is implemented. The caller must hold the lock.
+func (c *Cache[K, V]) peekItem(key K) (item *Item[K, V], value V, ok bool) {
+	item, ok = c.lookup(key)
+	if !ok || c.expired(item) {
+		return nil, value, false
+	}
+	value, ok = item.load()
+	if !ok {
+		return nil, value, false
+	}
+	return item, value, true
+}
+
+// lookup looks up the item of key by Peek of the underlying cache if it is
+// implemented, regardless of the expiration. The caller must hold the lock.
+func (c *Cache[K, V]) lookup(key K) (*Item[K, V], bool) {
+	if pc, ok := c.cache.(PeekingInterface[K, *Item[K, V]]); ok {
+		return pc.Peek(key)
+	}
+	return c.cache.Get(key)
+}
+
+// Touch sets the expiration of the item of key to now + exp without reading
+// the value. The item is updated in place under the write lock.
+//
+// Returns false if the key is not found or has been expired.
+func (c *Cache[K, V]) Touch(key K, exp time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item, _, ok := c.get(key)
+	if !ok || c.frozen {
+		return false
+	}
+	c.untrack(item)
+	item.Expiration = nowFunc().Add(exp)
+	c.track(item)
+	return true
+}
+
+// GetMulti looks up the values of keys from the cache under a single lock.
+// Missing and expired keys are omitted from the returned map.
+func (c *Cache[K, V]) GetMulti(keys []K) map[K]V {
+	if c.trace != nil {
+		for _, key := range keys {
+			c.trace.record(traceGet, key)
+		}
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	items := make(map[K]V, len(keys))
+	for _, key := range keys {
+		_, val, ok := c.get(key)
+		if !ok {
+			c.stats.miss()
+			continue
+		}
+		c.stats.hit()
+		items[key] = val
+	}
+	return items
+}
+
+// GetOrSet returns the existing value for the key if present and not expired.
+// Otherwise, it stores and returns the given value. The loaded result is true
+// if the value was loaded, false if stored. This is done under a single lock.
+//
+// If the value cannot be stored because the cache is frozen or full, e.g. with
+// OverflowReject, or the key is not admitted by WithAdmissionFilter, the zero
+// value and false are returned, so that the value which is neither loaded nor
+// stored is not mistaken for the cached one.
+func (c *Cache[K, V]) GetOrSet(key K, val V, opts ...ItemOption) (actual V, loaded bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	if _, actual, ok := c.get(key); ok {
+		return actual, true
+	}
+	if err := c.store(key, val, opts...); err != nil {
+		return actual, false
+	}
+	return val, false
+}
+
+// NoExpiration is returned by TTL for an item which never expires.
+const NoExpiration time.Duration = math.MaxInt64
+
+// Oldest returns the oldest item of the cache replacement policy w/o removing
+// it, such as the first inserted item of the FIFO cache or the least recently
+// used item of the LRU cache. Expired items found
+// at the head are deleted. ok is false if the cache is empty or the policy
+// does not support it.
+func (c *Cache[K, V]) Oldest() (key K, val V, ok bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	item, val, ok := c.oldest()
+	if !ok {
+		return key, val, false
+	}
+	return item.Key, val, true
+}
+
+// RemoveOldest deletes the oldest item like Delete, and returns it. This lets
+// the FIFO cache be drained in insertion order. ok is false if the cache is
+// empty or the policy does not support it.
+func (c *Cache[K, V]) RemoveOldest() (key K, val V, ok bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	item, val, ok := c.oldest()
+	if !ok || c.frozen {
+		return key, val, false
+	}
+	c.delete(item.Key)
+	return item.Key, val, true
+}
+
+// oldest returns the oldest live item, deleting expired ones at the head.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) oldest() (item *Item[K, V], val V, ok bool) {
+	var peek func() (K, *Item[K, V], bool)
+	switch oc := c.cache.(type) {
+	case interface {
+		Oldest() (key K, val *Item[K, V], ok bool)
+	}:
+		peek = oc.Oldest
+	case interface {
+		PeekOldest() (key K, val *Item[K, V], ok bool)
+	}:
+		peek = oc.PeekOldest
+	default:
+		return nil, val, false
+	}
+	for {
+		key, item, ok := peek()
+		if !ok {
+			return nil, val, false
+		}
+		if c.deleteExpired(key) {
+			continue
+		}
+		if c.expired(item) {
+			// the cache is frozen.
+			return nil, val, false
+		}
+		val, _ = item.load()
+		return item, val, true
+	}
+}
+
+// Swap sets a value to the cache with key like Set, and returns the previous
+// value if any. replaced reports whether the previous item was present and not
+// expired. This is done under a single lock.
+//
+// If the value cannot be stored because the cache is frozen or full with
+// OverflowReject, the previous item is kept, and the zero value and false are
+// returned.
+func (c *Cache[K, V]) Swap(key K, val V, opts ...ItemOption) (previous V, replaced bool) {
+	if c.trace != nil {
+		c.trace.record(traceSet, key)
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	_, previous, replaced = c.peekItem(key)
+	if err := c.store(key, val, opts...); err != nil {
+		var zero V
+		return zero, false
+	}
+	return previous, replaced
+}
+
+// TTL returns the remaining time until the item of key expires. If the item
+// never expires, it returns NoExpiration. ok is false if the key is not found
+// or the item has been expired. The cache replacement policy is not affected.
+func (c *Cache[K, V]) TTL(key K) (d time.Duration, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, _, ok := c.peekItem(key)
+	if !ok {
+		return 0, false
+	}
+	if item.Expiration.IsZero() {
+		return NoExpiration, true
+	}
+	return item.Expiration.Sub(nowFunc()), true
+}
+
+// IsExpired reports whether the item of key exists in the cache, and whether
+// it has been expired but not deleted yet, w/o deleting it or affecting the
+// cache replacement policy or statistics. It tells an absent key from an
+// expired one which Get does not return either.
+//
+// Items stored before the latest BumpEpoch and the items whose weakly held
+// values have been collected are also reported as expired.
+func (c *Cache[K, V]) IsExpired(key K) (expired bool, exists bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, ok := c.lookup(key)
+	if !ok {
+		return false, false
+	}
+	return c.expired(item), true
+}
+
+// Add sets a value to the cache with key only if the key is not present or the
+// item has been expired. Returns true if the value has been stored.
+// This is done under a single lock.
+func (c *Cache[K, V]) Add(key K, val V, opts ...ItemOption) bool {
+	c.mu.Lock()
+	defer c.unlock()
+	if _, _, ok := c.get(key); ok {
+		return false
+	}
+	return c.store(key, val, opts...) == nil
+}
+
+// SetNX sets a value to the cache with key which expires after ttl only if the
+// key is not present or the item has been expired, like SET NX EX of Redis.
+// It is a shorthand of Add with WithExpiration, and returns true if the value
+// has been stored. If ttl is not positive, the default expiration of the cache
+// is applied instead.
+//
+// This can be used as a lock with a lease, e.g. to let only one goroutine
+// refresh a value, but note that it is an in-process primitive, which does
+// not exclude other processes.
+func (c *Cache[K, V]) SetNX(key K, val V, ttl time.Duration) bool {
+	if ttl <= 0 {
+		return c.Add(key, val)
+	}
+	return c.Add(key, val, WithExpiration(ttl))
+}
+
+// Replace sets a value to the cache with key only if a non-expired item already
+// exists for the key. Returns true if the value has been replaced.
+// This is done under a single lock.
+func (c *Cache[K, V]) Replace(key K, val V, opts ...ItemOption) bool {
+	c.mu.Lock()
+	defer c.unlock()
+	if _, _, ok := c.get(key); !ok || c.frozen {
+		return false
+	}
+	c.set(key, val, opts...)
+	return true
+}
+
+// GetAndDelete deletes the item for a key, returning the previous value if any.
+// ok reports whether the item was present and not expired. An expired item is
+// deleted as well. This is done under a single lock.
+func (c *Cache[K, V]) GetAndDelete(key K) (value V, ok bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.frozen {
+		return
+	}
+	item, ok := c.cache.Get(key)
+	if !ok {
+		return
+	}
+	c.delete(key)
+	if c.expired(item) {
+		return value, false
+	}
+	return item.load()
+}
+
+// CompareAndDelete deletes the item for a key only if its current value is
+// equal to old, and reports whether it was deleted. An expired item is never
+// equal to old. This is done under a single lock.
+//
+// The values are compared by ==, so it panics if V is not comparable like
+// sync.Map.CompareAndDelete does.
+func (c *Cache[K, V]) CompareAndDelete(key K, old V) bool {
+	c.mu.Lock()
+	defer c.unlock()
+	_, val, ok := c.peekItem(key)
+	if !ok || c.frozen || any(val) != any(old) {
+		return false
+	}
+	c.delete(key)
+	return true
+}
+
+// get returns the item of key and its value if the item is present and not
+// expired, and records the access to the cache replacement policy. Expired
+// items are looked up by peekItem not to be promoted by the policy.
+// The caller must hold the lock.
+func (c *Cache[K, V]) get(key K) (item *Item[K, V], value V, ok bool) {
+	item, value, ok = c.peekItem(key)
+	if !ok {
+		return nil, value, false
+	}
+	c.cache.Get(key)
+	return item, value, true
+}
+
+// Update replaces the value of key with the value returned by fn, or deletes
+// the item if fn returns false. fn is passed the current value, and exists
+// reports whether the key is found and has not been expired. The expiration
+// of the existing item is kept.
+//
+// While fn runs, Update holds a lock of the key instead of the lock of the
+// whole cache, so updates of different keys run concurrently, and updates of
+// the same key are applied one by one in the order they acquire the lock.
+// Note that only Update calls are serialized by the lock, Set and Delete of
+// the key are not. fn may call back into the cache, but must not call Update
+// for the same key, otherwise it deadlocks.
+func (c *Cache[K, V]) Update(key K, fn func(old V, exists bool) (V, bool)) {
+	unlock := c.keyLocks.lock(key)
+	defer unlock()
+
+	c.mu.RLock()
+	_, old, exists := c.peekItem(key)
+	c.mu.RUnlock()
+
+	val, ok := fn(old, exists)
+	if !ok {
+		c.Delete(key)
+		return
+	}
+	c.Set(key, val, c.expirationOf(key))
+}
+
+// UpdateField updates the value of key in place under the write lock.
+//
+// fn is passed a pointer to a copy of the stored value, and the mutated copy
+// is stored back keeping the expiration of the item. Note that the copy is
+// shallow, so maps, slices and pointers in the value are still shared.
+// fn must not call back into the cache.
+//
+// Returns false and fn is not called if the key is not found or has been expired.
+func (c *Cache[K, V]) UpdateField(key K, fn func(*V)) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	item, val, ok := c.get(key)
+	if !ok || c.frozen {
+		return false
+	}
+	fn(&val)
+	item.store(val, c.weakRef)
+	c.publish(EventSet, item)
+	return true
+}
+
+// MarkAbsent records that the key is known to be absent from the backend.
+// It does nothing unless the cache is created with WithNegativeBloom.
+func (c *Cache[K, V]) MarkAbsent(key K) {
+	if c.absent != nil {
+		c.absent.add(key)
+	}
+}
+
+// MaybeAbsent reports whether the key may have been marked by MarkAbsent, so a
+// loader call for the key can be skipped. false means the key has definitely
+// not been marked. Because of false positives of the Bloom filter, true may be
+// returned for a key which has never been marked.
+func (c *Cache[K, V]) MaybeAbsent(key K) bool {
+	if c.absent == nil {
+		return false
+	}
+	return c.absent.contains(key)
+}
+
+// ResetAdmission clears all of the keys recorded by the admission filter, so
+// every new key must be set twice again to be stored. It does nothing unless
+// the cache is created with WithAdmissionFilter.
+func (c *Cache[K, V]) ResetAdmission() {
+	if c.admission != nil {
+		c.admission.reset()
+	}
+}
+
+// admit reports whether the new key is admitted by the admission filter, and
+// records it if not. The caller must hold the write lock.
+func (c *Cache[K, V]) admit(key K) bool {
+	if c.admission == nil {
+		return true
+	}
+	if _, ok := c.lookup(key); ok || c.admission.contains(key) {
+		return true
+	}
+	c.admission.add(key)
+	return false
+}
+
+// ResetAbsent clears all of the keys marked by MarkAbsent.
+func (c *Cache[K, V]) ResetAbsent() {
+	if c.absent != nil {
+		c.absent.reset()
+	}
+}
+
+// expireBatchSize is the number of keys which DeleteExpired examines under
+// a single lock.
+const expireBatchSize = 1024
+
+// DeleteExpired all expired items from the cache.
+//
+// The expired keys are taken at the beginning, and they are examined in
+// batches under the lock, so other goroutines are not blocked during the
+// whole pass. Items which are set during the pass are not examined until the
+// next call. The eviction order of the cache replacement policy is not
+// affected.
+//
+// The items with an expiration are indexed by the expiration, so only the
+// expired items are examined in O(k log n) time for k expired items of n. All
+// of the items are scanned instead after BumpEpoch, or with WithWeakValues.
+// Nothing is done while no item has an expiration.
+func (c *Cache[K, V]) DeleteExpired() {
+	c.mu.Lock()
+	c.deleteNotFound()
+	if c.frozen || !c.mayExpire() {
+		// no need to scan the items which never expire.
+		c.mu.Unlock()
+		return
+	}
+	// only the indexed items can be expired unless the items stored before
+	// the latest BumpEpoch or weakly held values are left.
+	indexed := !c.staleEpoch && c.weakRef == nil
+	var keys []K
+	if indexed {
+		keys = c.expiries.popExpired(nowFunc(), c.expiries.len())
+	} else {
+		keys = c.cache.Keys()
+		c.staleEpoch = false
+	}
+	c.mu.Unlock()
+
+	for len(keys) > 0 {
+		n := expireBatchSize
+		if n > len(keys) {
+			n = len(keys)
+		}
+		c.mu.Lock()
+		for _, key := range keys[:n] {
+			if c.deleteExpired(key) || !indexed {
+				continue
+			}
+			// the item has been renewed, or the cache has been frozen.
+			if item, ok := c.lookup(key); ok {
+				c.track(item)
+			}
+		}
+		c.unlock()
+		keys = keys[n:]
+	}
+}
+
+// deleteExpired deletes the item of key if it has been expired, and reports
+// whether it has been deleted. The caller must hold the write lock.
+func (c *Cache[K, V]) deleteExpired(key K) bool {
+	if c.frozen {
+		return false
+	}
+	item, ok := c.lookup(key)
+	if !ok || !c.expired(item) {
+		return false
+	}
+	c.remove(key, EventExpire)
+	c.stats.expired()
+	c.expire(item)
+	return true
+}
+
+// mayExpire reports whether any item may have been expired. Items w/o
+// expiration are expired only by BumpEpoch or weak references. The caller
+// must hold the lock.
+func (c *Cache[K, V]) mayExpire() bool {
+	return c.expiries.len() > 0 || c.staleEpoch || c.weakRef != nil
+}
+
+// track indexes the item by its expiration if it has one, and by its tags.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) track(item *Item[K, V]) {
+	c.tag(item)
+	if item.Expiration.IsZero() {
+		c.expiries.remove(item.Key)
+		return
+	}
+	c.expiries.set(item.Key, item.Expiration)
+}
+
+// untrack removes the item which has been removed or is about to change its
+// expiration from the indexes. The caller must hold the write lock.
+func (c *Cache[K, V]) untrack(item *Item[K, V]) {
+	c.expiries.remove(item.Key)
+	c.untag(item)
+}
+
+// PurgeExpired deletes all expired items from the cache on demand, w/o waiting
+// for the janitor. It is an alias of DeleteExpired.
+func (c *Cache[K, V]) PurgeExpired() {
+	c.DeleteExpired()
+}
+
+// SetJanitorInterval changes how often the janitor of the cache deletes expired
+// items, w/o recreating the cache. The next sweep is d after the call.
+//
+// It does nothing if d is not positive, the cache uses the shared janitor pool
+// or the janitor has been stopped.
+func (c *Cache[K, V]) SetJanitorInterval(d time.Duration) {
+	if d <= 0 || c.janitor == nil {
+		return
+	}
+	c.janitor.setInterval(d)
+}
+
+// DeleteExpiredN deletes expired items like DeleteExpired, but examines at most
+// maxKeys keys per call, so at most maxKeys items are reaped.
+//
+// The expired items are taken from the earliest expiration by the index of
+// expirations, so each call takes O(maxKeys log n) time regardless of the
+// number of items n. done reports whether no expired item is left.
+//
+// After BumpEpoch, or with WithWeakValues, any item may be expired, so a pass
+// over the keys of the whole cache which are taken at the start of the pass
+// is resumed across calls instead. done reports whether the pass has been
+// finished then.
+func (c *Cache[K, V]) DeleteExpiredN(maxKeys int) (reaped int, done bool) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	n := maxKeys
+	if n < 0 {
+		n = 0
+	}
+	if c.frozen {
+		return 0, true
+	}
+	if c.sweep == nil && !c.mayExpire() {
+		return 0, true
+	}
+	if c.sweep == nil && !c.staleEpoch && c.weakRef == nil {
+		now := nowFunc()
+		for _, key := range c.expiries.popExpired(now, n) {
+			if c.deleteExpired(key) {
+				reaped++
+			} else if item, ok := c.lookup(key); ok {
+				c.track(item)
+			}
+		}
+		return reaped, !c.expiries.expired(now)
+	}
+	if c.sweep == nil {
+		c.sweep = c.cache.Keys()
+		c.staleEpoch = false
+	}
+	if n > len(c.sweep) {
+		n = len(c.sweep)
+	}
+	for _, key := range c.sweep[:n] {
+		if c.deleteExpired(key) {
+			reaped++
+		}
+	}
+	c.sweep = c.sweep[n:]
+	if len(c.sweep) == 0 {
+		c.sweep = nil
+		return reaped, true
+	}
+	return reaped, false
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+//
+// If the cache is full and created with WithOverflowPolicy other than
+// OverflowEvict, the value may not be stored. Use TrySet to know it.
+func (c *Cache[K, V]) Set(key K, val V, opts ...ItemOption) {
+	_ = c.TrySet(key, val, opts...)
+}
+
+// TrySet sets a value to the cache with key like Set, and reports whether the
+// value has been stored according to the overflow policy of the cache.
+//
+// Returns ErrCacheFull if the cache is full and the policy is OverflowReject,
+// if the policy is OverflowBlock and no space is freed within the timeout, or
+// if the cache replacement policy refuses a new key. Returns ErrFrozen if the
+// cache is frozen by Freeze, and ErrNotAdmitted if the key is not admitted by
+// WithAdmissionFilter yet.
+func (c *Cache[K, V]) TrySet(key K, val V, opts ...ItemOption) error {
+	if c.trace != nil {
+		c.trace.record(traceSet, key)
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	return c.store(key, val, opts...)
+}
+
+// SetMulti sets all items to the cache under a single lock, replacing any existing values.
+// The item options are applied to every item.
+// Like Set, the items which cannot be stored because the cache is frozen or
+// full with OverflowReject are dropped silently. Use TrySet to know about it.
+func (c *Cache[K, V]) SetMulti(items map[K]V, opts ...ItemOption) {
+	if c.trace != nil {
+		for key := range items {
+			c.trace.record(traceSet, key)
+		}
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	for key, val := range items {
+		_ = c.store(key, val, opts...)
+	}
+}
+
+// store sets a value according to the overflow policy.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) store(key K, val V, opts ...ItemOption) error {
+	if c.frozen {
+		return ErrFrozen
+	}
+	if err := c.reserve(key); err != nil {
+		return err
+	}
+	return c.set(key, val, opts...)
+}
+
+// reserve makes sure that there is space to store a new item with key
+// according to the overflow policy. The caller must hold the write lock,
+// and it is released while waiting for space with OverflowBlock.
+func (c *Cache[K, V]) reserve(key K) error {
+	if c.overflow == OverflowEvict {
+		return nil
+	}
+	var timeout <-chan time.Time
+	for c.full(key) {
+		if c.overflow == OverflowReject {
+			return ErrCacheFull
+		}
+		if timeout == nil && c.overflowTimeout > 0 {
+			timer := time.NewTimer(c.overflowTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		if c.freed == nil {
+			c.freed = make(chan struct{})
+		}
+		freed := c.freed
+		c.mu.Unlock()
+		select {
+		case <-freed:
+			c.mu.Lock()
+		case <-timeout:
+			c.mu.Lock()
+			return ErrCacheFull
+		}
+	}
+	return nil
+}
+
+// full reports whether storing a new item with key needs to evict any items.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) full(key K) bool {
+	bounded, ok := c.cache.(interface {
+		Len() int
+		Cap() int
+	})
+	if !ok {
+		return false
+	}
+	// replacing an existing item does not need any space.
+	if _, exists := c.lookup(key); exists {
+		return false
+	}
+	// the capacity of the simple cache is 0 if it is unbounded.
+	return bounded.Cap() > 0 && bounded.Len() >= bounded.Cap()
+}
+
+// set stores a new item. Returns ErrNotAdmitted if the admission filter drops
+// the item, and ErrCacheFull if the cache replacement policy refuses a new
+// key, such as the simple cache with WithRejectWhenFull.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) set(key K, val V, opts ...ItemOption) error {
+	if c.frozen {
+		return ErrFrozen
+	}
+	random := jitterFunc
+	if c.jitter != nil {
+		random = c.jitter.Int63n
+	}
+	o := newItemOptions(random, opts...)
+	if o.epoch != nil && *o.epoch != c.epoch {
+		// the value was produced before the latest BumpEpoch.
+		return nil
+	}
+	if !c.admit(key) {
+		return ErrNotAdmitted
+	}
+	if o.expiration.IsZero() && !o.noExpiration && c.defaultExpiration > 0 {
+		o.expiration = nowFunc().Add(c.defaultExpiration + o.offset)
+	}
+	item := newItem(key, val, o)
+	item.epoch = c.epoch
+	item.store(val, c.weakRef)
+	old, replaced := c.lookup(key)
+	var evicted *Item[K, V]
+	var isEvicted bool
+	if ec, ok := c.cache.(EvictingInterface[K, *Item[K, V]]); ok {
+		_, evicted, isEvicted = ec.SetWithEvicted(key, item)
+		if _, stored := c.lookup(key); !stored && !(isEvicted && evicted == item) {
+			// the policy has refused the new key.
+			return ErrCacheFull
+		}
+	} else {
+		c.cache.Set(key, item)
+	}
+	if replaced {
+		c.untrack(old)
+	}
+	c.track(item)
+	if c.sizer != nil {
+		item.size = c.sizer(key, val)
+		if replaced {
+			c.bytes -= old.size
+		}
+		c.bytes += item.size
+		defer c.shrink()
+	}
+	c.publish(EventSet, item)
+	if isEvicted {
+		c.bytes -= evicted.size
+		c.untrack(evicted)
+		c.evict(evicted)
+		c.publish(EventEvict, evicted)
+		c.stats.evicted()
+	}
+	return nil
+}
+
+// evict queues the removed item for the eviction callback.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) evict(item *Item[K, V]) {
+	if c.onEvicted != nil {
+		c.evicted = append(c.evicted, item)
+	}
+}
+
+// expire queues the item deleted due to its expiration for the expired callback.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) expire(item *Item[K, V]) {
+	if c.onExpired != nil && item.Expired() {
+		c.expiredItems = append(c.expiredItems, item)
+	}
+}
+
+// unlock releases the write lock, and then calls the eviction callback and
+// the expired callback for the items removed while holding the lock.
+func (c *Cache[K, V]) unlock() {
+	evicted, expired := c.evicted, c.expiredItems
+	c.evicted, c.expiredItems = nil, nil
+	c.mu.Unlock()
+	for _, item := range evicted {
+		val, _ := item.load()
+		c.onEvicted(item.Key, val)
+	}
+	for _, item := range expired {
+		val, _ := item.load()
+		c.onExpired(item.Key, val)
+	}
+}
+
+// expired reports whether the item has been expired, was stored before
+// the latest BumpEpoch or its weakly held value has been collected.
+// The caller must hold the lock.
+func (c *Cache[K, V]) expired(item *Item[K, V]) bool {
+	if item.Expired() || item.epoch != c.epoch {
+		return true
+	}
+	_, ok := item.load()
+	return !ok
+}
+
+// Epoch returns the current epoch of the cache.
+func (c *Cache[K, V]) Epoch() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.epoch
+}
+
+// BumpEpoch advances the epoch of the cache and returns the new one.
+//
+// All items stored before the call are treated as absent from then on
+// without iterating over them, and they are lazily deleted by the janitor.
+func (c *Cache[K, V]) BumpEpoch() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.frozen {
+		c.epoch++
+		c.staleEpoch = c.cache.Len() > 0
+	}
+	return c.epoch
+}
+
+// Len returns the number of items in the cache without allocating.
+// Note that it counts expired items which have not been deleted by the janitor yet.
+func (c *Cache[K, V]) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cache.Len()
+}
+
+// LiveLen returns the number of items in the cache which have not expired,
+// unlike Len. It examines every item under the read lock, so it is O(n) and
+// should be called infrequently, e.g. for metrics.
+func (c *Cache[K, V]) LiveLen() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	n := 0
+	for _, key := range c.cache.Keys() {
+		if item, ok := c.lookup(key); ok && !c.expired(item) {
+			n++
+		}
+	}
+	return n
+}
+
+// Resize changes the capacity of the cache replacement policy to n. When
+// shrinking, items are evicted in the order of the policy until the cache fits
+// in n, and the eviction callback is called for each of them. Growing does not
+// touch existing items.
+//
+// A negative n is treated as 0.
+//
+// Returns the number of evicted items. It does nothing and returns 0 if the
+// policy does not support resizing. All of the policies in this module do.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	if n < 0 {
+		n = 0
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	rc, ok := c.cache.(interface {
+		Resize(n int) (evicted int)
+		Evict() (key K, val *Item[K, V], ok bool)
+	})
+	if !ok || c.frozen {
+		return 0
+	}
+	for c.cache.Len() > n {
+		if _, ok := c.evictNext(rc); !ok {
+			break
+		}
+		evicted++
+	}
+	if shrunk := rc.Resize(n); shrunk > 0 {
+		evicted += shrunk
+		c.generation++
+		// the items evicted by the policy are unknown.
+		c.expiries.clear()
+		c.tags = nil
+		for _, key := range c.cache.Keys() {
+			if item, ok := c.lookup(key); ok {
+				c.track(item)
+			}
+		}
+	}
+	if c.freed != nil {
+		// wakes up Set calls waiting for space.
+		close(c.freed)
+		c.freed = nil
+	}
+	return evicted
+}
+
+// EvictOldest evicts the item which the cache replacement policy would evict
+// next, such as the least recently used item of the LRU cache, and returns it.
+// The eviction callback is called for it as if it has been evicted to make
+// room for a new item. This lets items be evicted manually, e.g. until enough
+// memory is released.
+//
+// ok is false if the cache is empty or the policy does not support it. All of
+// the policies in this module do.
+func (c *Cache[K, V]) EvictOldest() (key K, val V, ok bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	ec, ok := c.cache.(interface {
+		Evict() (key K, val *Item[K, V], ok bool)
+	})
+	if !ok {
+		return key, val, false
+	}
+	item, ok := c.evictNext(ec)
+	if !ok {
+		return key, val, false
+	}
+	if c.freed != nil {
+		close(c.freed)
+		c.freed = nil
+	}
+	val, _ = item.load()
+	return item.Key, val, true
+}
+
+// evictNext evicts the next item of the policy, and queues it for the eviction
+// callback. The caller must hold the write lock.
+func (c *Cache[K, V]) evictNext(ec interface {
+	Evict() (key K, val *Item[K, V], ok bool)
+}) (*Item[K, V], bool) {
+	if c.frozen {
+		return nil, false
+	}
+	_, item, ok := ec.Evict()
+	if !ok {
+		return nil, false
+	}
+	c.bytes -= item.size
+	c.untrack(item)
+	c.evict(item)
+	c.publish(EventEvict, item)
+	c.stats.evicted()
+	return item, true
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item which the
+// cache replacement policy would evict first, such as by EvictOldest, to the
+// item which would be evicted last. Expired items are included until they are
+// deleted. It returns nil if the policy does not support it. All of the
+// policies in this module do.
+//
+// The order is a snapshot like Keys, and the item evicted to make room for a
+// new item may differ for policies which adapt to the new item, such as ARC
+// and W-TinyLFU.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	// takes the write lock since the LFU policy applies its decay.
+	c.mu.Lock()
+	defer c.unlock()
+	ko, ok := c.cache.(interface{ KeysInEvictionOrder() []K })
+	if !ok {
+		return nil
+	}
+	return ko.KeysInEvictionOrder()
+}
+
+// Keys returns the keys of the cache. the order is relied on algorithms.
+//
+// The keys are a snapshot taken under the read lock, so they may be stale as
+// soon as Keys returns if the cache is mutated concurrently. Use KeysSnapshot
+// to detect it.
+func (c *Cache[K, V]) Keys() []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cache.Keys()
+}
+
+// KeysPage returns a page of the keys of the cache: at most limit keys in the
+// order of Keys, skipping the first offset keys. It returns an empty slice if
+// offset is not less than the number of items. Like Keys, expired items which
+// have not been deleted yet are included.
+//
+// The page is a point-in-time snapshot taken under the read lock, so pages
+// taken by successive calls may overlap or miss keys if the cache is modified
+// in between. The LRU, MRU and FIFO cache replacement policies walk only the
+// keys up to the page, and the other policies enumerate all keys internally.
+func (c *Cache[K, V]) KeysPage(offset, limit int) []K {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if pc, ok := c.cache.(interface {
+		KeysPage(offset, limit int) []K
+	}); ok {
+		return pc.KeysPage(offset, limit)
+	}
+	keys := c.cache.Keys()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > len(keys) {
+		offset = len(keys)
+	}
+	keys = keys[offset:]
+	if limit < 0 {
+		limit = 0
+	}
+	if limit > len(keys) {
+		limit = len(keys)
+	}
+	// copies the page so that the rest of the keys can be collected.
+	return append([]K{}, keys[:limit]...)
+}
+
+// KeysSnapshot returns the keys of the cache like Keys, and the generation of
+// the cache when they were taken.
+func (c *Cache[K, V]) KeysSnapshot() (keys []K, generation uint64) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cache.Keys(), c.generation
+}
+
+// Generation returns the generation of the cache, which is advanced by each
+// change of the items such as Set, Delete, eviction and expiration. If it has
+// not changed, neither have the keys and values of the cache. Note that it is
+// not advanced when only the expiration of an item is renewed.
+func (c *Cache[K, V]) Generation() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.generation
+}
+
+// Range calls fn sequentially for each non-expired item in the cache in the
+// order of Keys. If fn returns false, Range stops the iteration. The cache
+// replacement policy is not affected.
+//
+// Range holds the read lock during the iteration, so fn must not call back
+// into the cache, otherwise it may deadlock.
+func (c *Cache[K, V]) Range(fn func(key K, val V) bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, key := range c.cache.Keys() {
+		_, val, ok := c.peekItem(key)
+		if !ok {
+			continue
+		}
+		if !fn(key, val) {
+			return
+		}
+	}
+}
+
+func (c *Cache[K, V]) List() map[K]V {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 
 	keys := c.cache.Keys()
+	items := make(map[K]V, len(keys))
 	for _, v := range keys {
-		c.cache.Delete(v)
+		item, ok := c.lookup(v)
+		if !ok {
+			continue
+		}
+		if val, ok := item.load(); ok {
+			items[v] = val
+		}
+	}
+
+	return items
+}
+
+// Close stops the janitor of the cache and waits for it to finish, or
+// unregisters the cache from the shared janitor pool. The cache is still
+// usable after Close, but expired items are not deleted in the background.
+// The remaining items are removed as Flush does with WithEvictionOnClose.
+//
+// Close is idempotent, and it always returns nil.
+func (c *Cache[K, V]) Close() error {
+	c.closeOnce.Do(func() {
+		if c.janitor != nil {
+			c.janitor.stop()
+			c.janitor.wait()
+		}
+		if c.pool != nil {
+			c.pool.unregister(c)
+		}
+		if c.evictOnClose {
+			c.Flush()
+		}
+	})
+	return nil
+}
+
+// Flush deletes all items from the cache like Clear.
+func (c *Cache[K, V]) Flush() {
+	c.Clear()
+}
+
+// Clear deletes all items from the cache, and returns the number of them.
+// The eviction callback is called for each of them.
+//
+// The cache replacement policy is reset to the empty state at once if it
+// supports it, otherwise the items are deleted one by one. All of the policies
+// in this module support it.
+func (c *Cache[K, V]) Clear() int {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.frozen {
+		return 0
+	}
+
+	cc, ok := c.cache.(interface{ Clear() })
+	if !ok {
+		keys := c.cache.Keys()
+		for _, key := range keys {
+			c.delete(key)
+		}
+		return len(keys)
+	}
+	n := c.cache.Len()
+	c.stats.deleted(n)
+	if c.onEvicted != nil || len(c.subscribers) > 0 {
+		for _, key := range c.cache.Keys() {
+			if item, ok := c.lookup(key); ok {
+				c.evict(item)
+				c.publish(EventDelete, item)
+			}
+		}
 	}
+	cc.Clear()
+	c.generation++
+	c.bytes = 0
+	c.expiries.clear()
+	c.tags = nil
+	c.sweep = nil
+	if c.freed != nil {
+		// wakes up Set calls waiting for space.
+		close(c.freed)
+		c.freed = nil
+	}
+	return n
 }
 
 // Delete deletes the item with provided key from the cache.
 func (c *Cache[K, V]) Delete(key K) {
+	if c.trace != nil {
+		c.trace.record(traceDelete, key)
+	}
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
+	c.delete(key)
+}
+
+// DeleteMulti deletes the items with provided keys from the cache under a single lock.
+func (c *Cache[K, V]) DeleteMulti(keys []K) {
+	if c.trace != nil {
+		for _, key := range keys {
+			c.trace.record(traceDelete, key)
+		}
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	for _, key := range keys {
+		c.delete(key)
+	}
+}
+
+// delete deletes the item from the underlying cache, queues it for the eviction
+// callback and wakes up Set calls waiting for space. The caller must hold the write lock.
+func (c *Cache[K, V]) delete(key K) {
+	c.remove(key, EventDelete)
+}
+
+// remove deletes the item like delete, and publishes the event of op for it.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) remove(key K, op EventOp) {
+	if c.frozen {
+		return
+	}
+	if item, ok := c.lookup(key); ok {
+		c.bytes -= item.size
+		c.untrack(item)
+		c.evict(item)
+		c.publish(op, item)
+		if op == EventDelete {
+			c.stats.deleted(1)
+		}
+	}
 	c.cache.Delete(key)
+	if c.freed != nil {
+		close(c.freed)
+		c.freed = nil
+	}
 }
 
 // Contains reports whether key is within cache.
 func (c *Cache[K, V]) Contains(key K) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	_, ok := c.cache.Get(key)
+	_, ok := c.lookup(key)
 	return ok
 }
 
+// expirationOf returns an option to carry forward the expiration of the live
+// item of key to a new value. The option does nothing if the key is not found.
+func (c *Cache[K, V]) expirationOf(key K) ItemOption {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	item, _, ok := c.peekItem(key)
+	if !ok {
+		return func(*itemOptions) {}
+	}
+	return keepExpiration(item)
+}
+
+// keepExpiration returns an option to carry forward the expiration of item,
+// and its tags as well.
+func keepExpiration[K comparable, V any](item *Item[K, V]) ItemOption {
+	exp, sliding, tags := item.Expiration, item.sliding, item.tags
+	return func(o *itemOptions) {
+		withExpirationTime(exp)(o)
+		o.sliding = sliding
+		// WithTags must not append to the tags of item in place.
+		o.tags = tags[:len(tags):len(tags)]
+	}
+}
+
 // NumberCache is a in-memory cache which is able to store only Number constraint.
 type NumberCache[K comparable, V Number] struct {
 	*Cache[K, V]
-	// nmu is used to do lock in Increment/Decrement process.
-	// Note that this must be here as a separate mutex because mu in Cache struct is Locked in Get,
-	// and if we call mu.Lock in Increment/Decrement, it will cause deadlock.
-	nmu sync.Mutex
+	// onIncrement is called after each Increment/Decrement outside the locks.
+	onIncrement func(key K, delta, newValue V)
+}
+
+// WithIncrementCallback is an option to be notified of changes by Increment/Decrement
+// of NumberCache. fn is called after each change outside the locks with the
+// applied delta and the resulting value.
+//
+// The delta is n for Increment and -n for Decrement. For unsigned types it
+// wraps around as the value itself does. This option is ignored by New.
+func WithIncrementCallback[K comparable, V any](fn func(key K, delta, newValue V)) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.incrementCallback = fn
+	}
 }
 
 // NewNumber creates a new cache for Number constraint.
 func NewNumber[K comparable, V Number](opts ...Option[K, V]) *NumberCache[K, V] {
+	o := newOptions[K, V]()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
 	return &NumberCache[K, V]{
-		Cache: New(opts...),
+		Cache:       newCache(context.Background(), o),
+		onIncrement: o.incrementCallback,
 	}
 }
 
-// Increment an item of type Number constraint by n.
-// Returns the incremented value.
+// Increment an item of type Number constraint by n. A missing key is treated
+// as zero. See Number for the caveat of floating-point values.
+// Returns the incremented value. The expiration of the existing item is kept.
+// If the value cannot be stored because the cache is frozen or full with
+// OverflowReject, the current value is returned and the increment callback is
+// not called.
 func (nc *NumberCache[K, V]) Increment(key K, n V) V {
-	// In order to avoid lost update, we must lock whole Increment/Decrement process.
-	nc.nmu.Lock()
-	defer nc.nmu.Unlock()
-	got, _ := nc.Cache.Get(key)
-	nv := got + n
-	nc.Cache.Set(key, nv)
+	nv, ok := nc.increment(key, n)
+	if ok {
+		nc.notifyIncrement(key, n, nv)
+	}
 	return nv
 }
 
+func (nc *NumberCache[K, V]) increment(key K, n V) (V, bool) {
+	return nc.update(key, func(got V, _ bool) (V, bool) {
+		return got + n, true
+	})
+}
+
+// IncrementExisting increments an item of type Number constraint by n like
+// Increment, but only if the key is present and has not been expired. Unlike
+// Increment, it does not create an item for a missing key.
+//
+// Returns the incremented value and true, or the zero value and false if the
+// key is not found.
+func (nc *NumberCache[K, V]) IncrementExisting(key K, n V) (V, bool) {
+	nv, ok := nc.update(key, func(got V, exists bool) (V, bool) {
+		return got + n, exists
+	})
+	if ok {
+		nc.notifyIncrement(key, n, nv)
+	}
+	return nv, ok
+}
+
+// DecrementExisting decrements an item of type Number constraint by n like
+// Decrement, but only if the key is present and has not been expired.
+//
+// Returns the decremented value and true, or the zero value and false if the
+// key is not found.
+func (nc *NumberCache[K, V]) DecrementExisting(key K, n V) (V, bool) {
+	nv, ok := nc.update(key, func(got V, exists bool) (V, bool) {
+		return got - n, exists
+	})
+	if ok {
+		nc.notifyIncrement(key, -n, nv)
+	}
+	return nv, ok
+}
+
+// update replaces the value of key with the value returned by fn, keeping the
+// expiration of the item. fn is passed the current value, and exists reports
+// whether the key is found and has not been expired. The value is not replaced if fn returns false, and
+// then the current value and false are returned. So are they if the value
+// cannot be stored because the cache is frozen or full with OverflowReject.
+//
+// The whole read-modify-write is done under the write lock of the cache, so
+// it is atomic against all other operations of the cache, such as Set of the
+// same key. fn must not call back into the cache.
+func (nc *NumberCache[K, V]) update(key K, fn func(got V, exists bool) (V, bool)) (V, bool) {
+	c := nc.Cache
+	if c.trace != nil {
+		c.trace.record(traceGet, key)
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	item, got, found := c.get(key)
+	if found {
+		c.stats.hit()
+	} else {
+		c.stats.miss()
+	}
+	nv, ok := fn(got, found)
+	if !ok {
+		return got, false
+	}
+	if c.trace != nil {
+		c.trace.record(traceSet, key)
+	}
+	var opts []ItemOption
+	if found {
+		opts = append(opts, keepExpiration(item))
+	}
+	if err := c.store(key, nv, opts...); err != nil {
+		return got, false
+	}
+	return nv, true
+}
+
+// IncrementBounded increments an item of nc by n unless the result exceeds
+// max. n must not be negative. It is a function instead of a method of
+// NumberCache because complex numbers of the Number constraint are not ordered.
+//
+// Returns the resulting value and whether the increment was applied. If it
+// was not, the current value is returned.
+func IncrementBounded[K comparable, V Real](nc *NumberCache[K, V], key K, n, max V) (V, bool) {
+	nv, ok := nc.update(key, func(got V, _ bool) (V, bool) {
+		// compares w/o computing got + n, which may overflow. max - n wraps
+		// around only if no value is small enough to be incremented.
+		if got > max || max-n > max || got > max-n {
+			return got, false
+		}
+		return got + n, true
+	})
+	if ok {
+		nc.notifyIncrement(key, n, nv)
+	}
+	return nv, ok
+}
+
+// DecrementBounded decrements an item of nc by n unless the result falls below
+// min. n must not be negative.
+//
+// Returns the resulting value and whether the decrement was applied. If it
+// was not, the current value is returned.
+func DecrementBounded[K comparable, V Real](nc *NumberCache[K, V], key K, n, min V) (V, bool) {
+	nv, ok := nc.update(key, func(got V, _ bool) (V, bool) {
+		if got < min || min+n < min || got < min+n {
+			return got, false
+		}
+		return got - n, true
+	})
+	if ok {
+		nc.notifyIncrement(key, -n, nv)
+	}
+	return nv, ok
+}
+
 // Decrement an item of type Number constraint by n.
-// Returns the decremented value.
+// Returns the decremented value. The expiration of the existing item is kept.
+// The value is not stored in the same cases as Increment.
 func (nc *NumberCache[K, V]) Decrement(key K, n V) V {
-	nc.nmu.Lock()
-	defer nc.nmu.Unlock()
-	got, _ := nc.Cache.Get(key)
-	nv := got - n
-	nc.Cache.Set(key, nv)
+	nv, ok := nc.increment(key, -n)
+	if ok {
+		nc.notifyIncrement(key, -n, nv)
+	}
 	return nv
 }
+
+// notifyIncrement calls the increment callback if it is specified.
+// This must be called without holding any locks.
+func (nc *NumberCache[K, V]) notifyIncrement(key K, delta, newValue V) {
+	if nc.onIncrement != nil {
+		nc.onIncrement(key, delta, newValue)
+	}
+}
@@ -26,3 +26,132 @@ func TestDeletedCache(t *testing.T) {
 		t.Fatal("want false")
 	}
 }
+
+func TestBumpEpoch(t *testing.T) {
+	c := New[string, int]()
+	c.Set("a", 1)
+
+	epoch := c.Epoch()
+	if got := c.BumpEpoch(); got != epoch+1 {
+		t.Fatalf("want epoch %d but got %d", epoch+1, got)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("want false for the item stored before bump")
+	}
+
+	// a value produced before the bump must not be stored.
+	c.Set("b", 2, WithEpoch(epoch))
+	if _, ok := c.cache.Get("b"); ok {
+		t.Fatal("want the stale item not to be stored")
+	}
+
+	c.Set("c", 3, WithEpoch(c.Epoch()))
+	if got, ok := c.Get("c"); !ok || got != 3 {
+		t.Fatalf("want 3 true but got %d %v", got, ok)
+	}
+
+	c.DeleteExpired()
+	if _, ok := c.cache.Get("a"); ok {
+		t.Fatal("want the stale item to be deleted by DeleteExpired")
+	}
+}
+
+func TestExpiringCount(t *testing.T) {
+	c := New(AsLRU[string, int]())
+	c.Set("a", 1)
+	c.Set("b", 2, WithExpiration(time.Minute))
+	c.Set("c", 3, WithExpiration(-time.Second))
+	c.Set("b", 2) // replaced w/o expiration
+	c.Touch("a", time.Minute)
+	if c.expiries.len() != 2 {
+		t.Fatalf("want 2 expiring items but got %d", c.expiries.len())
+	}
+
+	c.DeleteExpired()
+	c.Delete("a")
+	if c.expiries.len() != 0 || c.Len() != 1 {
+		t.Fatalf("want only b w/o expiration but got %d expiring items of %d", c.expiries.len(), c.Len())
+	}
+	if c.mayExpire() {
+		t.Fatal("want DeleteExpired to skip the scan")
+	}
+
+	c.BumpEpoch()
+	if !c.mayExpire() {
+		t.Fatal("want the stale items to be examined after BumpEpoch")
+	}
+	c.DeleteExpired()
+	if c.mayExpire() || c.Len() != 0 {
+		t.Fatalf("want the stale item to be deleted but got %d items", c.Len())
+	}
+
+	c.Set("d", 4, WithExpiration(time.Minute))
+	c.Resize(0)
+	c.Set("e", 5, WithExpiration(time.Minute))
+	c.Clear()
+	if c.expiries.len() != 0 {
+		t.Fatalf("want no expiring items after Clear but got %d", c.expiries.len())
+	}
+}
+
+func TestDeleteExpiredIndexed(t *testing.T) {
+	now := time.Now()
+	reset := SetNowFunc(now)
+	defer reset()
+
+	c := New[string, int]()
+	c.Set("renewed", 1, WithExpiration(time.Second))
+	c.Set("renewed", 1, WithExpiration(time.Hour))
+	c.Set("touched", 2, WithExpiration(time.Second))
+	c.Touch("touched", time.Hour)
+	c.Set("sliding", 3, WithSlidingExpiration(time.Minute))
+	c.Set("expired", 4, WithExpiration(time.Second))
+	c.Set("permanent", 5)
+
+	SetNowFunc(now.Add(30 * time.Second))
+	c.Get("sliding")
+	SetNowFunc(now.Add(time.Minute))
+	c.DeleteExpired()
+
+	if got := c.Len(); got != 4 || c.Contains("expired") {
+		t.Fatalf("want only the expired item to be deleted but got %v", c.Keys())
+	}
+	if got := c.expiries.len(); got != 3 {
+		t.Fatalf("want 3 indexed items but got %d", got)
+	}
+
+	SetNowFunc(now.Add(2 * time.Hour))
+	c.DeleteExpired()
+	if got := c.Keys(); len(got) != 1 || got[0] != "permanent" {
+		t.Fatalf("want only the permanent item to be kept but got %v", got)
+	}
+}
+
+func BenchmarkDeleteExpiredFew(b *testing.B) {
+	for _, bench := range []struct {
+		name string
+		scan bool
+	}{
+		{"index", false},
+		{"scan", true},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			c := New(AsLRU[int, int]())
+			c.Resize(100000)
+			for i := 0; i < 100000; i++ {
+				c.Set(i, i, WithExpiration(time.Hour))
+			}
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				// a handful of the items are expired.
+				for i := 0; i < 10; i++ {
+					c.Set(i, i, WithExpiration(-time.Second))
+				}
+				c.staleEpoch = bench.scan
+				b.StartTimer()
+				c.DeleteExpired()
+			}
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package cache_test
 
 import (
+	"errors"
+	"math"
 	"math/rand"
+	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -12,6 +17,7 @@ import (
 	"github.com/gekatateam/go-generics-cache/policy/lfu"
 	"github.com/gekatateam/go-generics-cache/policy/lru"
 	"github.com/gekatateam/go-generics-cache/policy/mru"
+	"github.com/gekatateam/go-generics-cache/policy/simple"
 )
 
 func TestMultiThreadIncr(t *testing.T) {
@@ -35,6 +41,47 @@ func TestMultiThreadIncr(t *testing.T) {
 	}
 }
 
+func TestIncrementAtomicWithSet(t *testing.T) {
+	nc := cache.NewNumber[string, int]()
+	const workers, increments = 8, 1000
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < increments; j++ {
+				nc.Increment("counter", 1)
+			}
+		}()
+	}
+	// takes the counter by Swap concurrently. Every increment must be counted
+	// exactly once either by Swap or by the final value.
+	done := make(chan struct{})
+	taken := make(chan int)
+	go func() {
+		sum := 0
+		for {
+			select {
+			case <-done:
+				taken <- sum
+				return
+			default:
+				prev, _ := nc.Swap("counter", 0)
+				sum += prev
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	sum := <-taken
+
+	final, _ := nc.Get("counter")
+	if got := sum + final; got != workers*increments {
+		t.Fatalf("want %d increments but got %d", workers*increments, got)
+	}
+}
+
 func TestMultiThreadDecr(t *testing.T) {
 	nc := cache.NewNumber[string, int]()
 	nc.Set("counter", 100)
@@ -121,3 +168,1765 @@ func TestCallJanitor(t *testing.T) {
 		t.Errorf("want items is empty but got %d", len(keys))
 	}
 }
+
+func TestNewChecked(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		c, err := cache.NewChecked(
+			cache.AsLRU[string, int](lru.WithCapacity(2)),
+			cache.WithMaxBytes[string, int](10, func(string, int) int64 { return 1 }),
+		)
+		if err != nil {
+			t.Fatalf("want no error but got %v", err)
+		}
+		defer c.Close()
+		c.Set("a", 1)
+		if got, ok := c.Get("a"); !ok || got != 1 {
+			t.Errorf("want 1 but got %v, %v", got, ok)
+		}
+	})
+
+	for name, opts := range map[string][]cache.Option[string, int]{
+		"two policies":      {cache.AsLRU[string, int](), cache.AsLFU[string, int]()},
+		"negative capacity": {cache.AsLRU[string, int](lru.WithCapacity(-1))},
+		"negative fifo":     {cache.AsFIFO[string, int](fifo.WithCapacity(-1))},
+		"nil sizer":         {cache.WithMaxBytes[string, int](10, nil)},
+		"zero max bytes":    {cache.WithMaxBytes[string, int](0, func(string, int) int64 { return 1 })},
+		"lruk k":            {cache.AsLRUK[string, int](0)},
+		"nil policy":        {cache.WithPolicy[string, int](nil)},
+		"bloom rate":        {cache.WithNegativeBloom[string, int](100, 1.5)},
+		"overflow policy":   {cache.WithOverflowPolicy[string, int](cache.OverflowPolicy(-1))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			c, err := cache.NewChecked(opts...)
+			if !errors.Is(err, cache.ErrInvalidOption) {
+				t.Errorf("want ErrInvalidOption but got %v", err)
+			}
+			if c != nil {
+				t.Error("want nil cache")
+			}
+		})
+	}
+}
+
+func TestDisabledJanitor(t *testing.T) {
+	c := cache.New(
+		cache.WithJanitorInterval[string, int](0),
+	)
+	defer c.Close()
+
+	c.Set("1", 10, cache.WithExpiration(-time.Second))
+	c.Set("2", 20)
+
+	if _, ok := c.Get("1"); ok {
+		t.Error("want the expired item to be absent")
+	}
+	if got := c.Len(); got != 2 {
+		t.Errorf("want the expired item to linger but got %d items", got)
+	}
+	c.DeleteExpired()
+	if got := c.Len(); got != 1 {
+		t.Errorf("want 1 item after DeleteExpired but got %d", got)
+	}
+}
+
+func TestIsExpired(t *testing.T) {
+	c := cache.New(cache.WithJanitorInterval[string, int](0))
+	c.Set("live", 1, cache.WithExpiration(time.Hour))
+	c.Set("expired", 2, cache.WithExpiration(-time.Second))
+
+	for key, want := range map[string][2]bool{
+		"live":    {false, true},
+		"expired": {true, true},
+		"missing": {false, false},
+	} {
+		expired, exists := c.IsExpired(key)
+		if expired != want[0] || exists != want[1] {
+			t.Errorf("%s: want %v %v but got %v %v", key, want[0], want[1], expired, exists)
+		}
+	}
+	if _, exists := c.IsExpired("expired"); !exists || c.Len() != 2 {
+		t.Error("want the expired item to be kept")
+	}
+
+	c.BumpEpoch()
+	if expired, _ := c.IsExpired("live"); !expired {
+		t.Error("want the item of the previous epoch to be expired")
+	}
+}
+
+func TestKeysPage(t *testing.T) {
+	for name, c := range map[string]*cache.Cache[int, int]{
+		"lru":  cache.New(cache.AsLRU[int, int]()),
+		"fifo": cache.New(cache.AsFIFO[int, int]()),
+		"lfu":  cache.New(cache.AsLFU[int, int]()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 10; i++ {
+				c.Set(i, i)
+			}
+			keys := c.Keys()
+			for _, tc := range []struct {
+				offset, limit int
+				want          []int
+			}{
+				{0, 3, keys[:3]},
+				{8, 5, keys[8:]},
+				{-1, 2, keys[:2]},
+				{10, 3, []int{}},
+				{20, 3, []int{}},
+				{2, 0, []int{}},
+			} {
+				if got := c.KeysPage(tc.offset, tc.limit); !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("KeysPage(%d, %d): want %v but got %v", tc.offset, tc.limit, tc.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestReadOnlyAccessKeepsOrder(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int]())
+	c.Set("a", 1, cache.WithExpiration(time.Hour))
+	c.Set("b", 2)
+	c.Set("c", 3)
+	want := c.KeysInEvictionOrder()
+
+	c.TTL("a")
+	c.Range(func(string, int) bool { return true })
+	c.List()
+	c.Contains("a")
+	c.IsExpired("a")
+	c.Peek("a")
+
+	if got := c.KeysInEvictionOrder(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("want the eviction order %v to be kept but got %v", want, got)
+	}
+}
+
+func TestMustGet(t *testing.T) {
+	c := cache.New[string, int]()
+	c.Set("zero", 0)
+	c.Set("expired", 1, cache.WithExpiration(-time.Second))
+
+	if got := c.MustGet("zero"); got != 0 {
+		t.Errorf("want %v but got %v", 0, got)
+	}
+
+	for _, key := range []string{"missing", "expired"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("want panic for key %q", key)
+				}
+			}()
+			c.MustGet(key)
+		}()
+	}
+}
+
+func TestReusedExpirationOption(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	opts := []cache.ItemOption{cache.WithExpiration(time.Minute)}
+	c := cache.New[string, int]()
+	c.Set("a", 1, opts...)
+
+	// the expiration is relative to each Set, not to the option.
+	cache.SetNowFunc(now.Add(time.Hour))
+	c.Set("b", 2, opts...)
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("want a to be expired")
+	}
+	if got, ok := c.TTL("b"); !ok || got != time.Minute {
+		t.Fatalf("want the TTL of b to be 1m, but got %v, %v", got, ok)
+	}
+}
+
+func TestGetWithExpiration(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	c.Set("b", 2)
+	c.Set("c", 3, cache.WithSlidingExpiration(time.Minute))
+	c.Set("d", 4, cache.WithExpiration(-time.Second))
+
+	if v, exp, ok := c.GetWithExpiration("a"); !ok || v != 1 || !exp.Equal(now.Add(time.Minute)) {
+		t.Fatalf("want 1 with the expiration, but got %v %v %v", v, exp, ok)
+	}
+	if v, exp, ok := c.GetWithExpiration("b"); !ok || v != 2 || !exp.IsZero() {
+		t.Fatalf("want 2 w/o expiration, but got %v %v %v", v, exp, ok)
+	}
+	cache.SetNowFunc(now.Add(30 * time.Second))
+	if _, exp, ok := c.GetWithExpiration("c"); !ok || !exp.Equal(now.Add(90*time.Second)) {
+		t.Fatalf("want the renewed expiration, but got %v %v", exp, ok)
+	}
+	for _, key := range []string{"d", "e"} {
+		if _, _, ok := c.GetWithExpiration(key); ok {
+			t.Fatalf("want %q not to be found", key)
+		}
+	}
+}
+
+func TestGetAndTouchMany(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.Set("a", 1, cache.WithExpiration(time.Second))
+	c.Set("b", 2, cache.WithExpiration(time.Second))
+	c.Set("c", 3, cache.WithExpiration(-time.Second))
+
+	got := c.GetAndTouchMany([]string{"a", "b", "c", "d"}, time.Minute)
+	if len(got) != 2 || got["a"] != 1 || got["b"] != 2 {
+		t.Fatalf("want map[a:1 b:2] but got %v", got)
+	}
+
+	cache.SetNowFunc(now.Add(30 * time.Second))
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("want the expiration of a to be extended")
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Fatal("want the expired item not to be touched")
+	}
+}
+
+func TestPeek(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(2)))
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	c.Set("b", 2)
+
+	item, ok := c.Peek("a")
+	if !ok {
+		t.Fatal("want a to be found")
+	}
+	if item.Key != "a" || item.Value != 1 || !item.Expiration.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+	if _, ok := c.Peek("c"); ok {
+		t.Fatal("want c not to be found")
+	}
+
+	// a is still the least recently used item since Peek does not promote it.
+	c.Set("c", 3)
+	if c.Contains("a") {
+		t.Fatal("want a to be evicted")
+	}
+
+	cache.SetNowFunc(now.Add(2 * time.Minute))
+	c.Set("d", 4, cache.WithExpiration(-time.Second))
+	if _, ok := c.Peek("d"); ok {
+		t.Fatal("want the expired item not to be found")
+	}
+}
+
+func TestTouch(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.Set("a", 1, cache.WithExpiration(time.Second))
+	c.Set("b", 2, cache.WithExpiration(-time.Second))
+
+	if !c.Touch("a", time.Minute) {
+		t.Fatal("want a to be touched")
+	}
+	if c.Touch("b", time.Minute) {
+		t.Fatal("want the expired item not to be touched")
+	}
+	if c.Touch("c", time.Minute) {
+		t.Fatal("want the missing item not to be touched")
+	}
+
+	cache.SetNowFunc(now.Add(30 * time.Second))
+	if got, ok := c.Get("a"); !ok || got != 1 {
+		t.Fatalf("want 1 true but got %d %v", got, ok)
+	}
+}
+
+func TestMulti(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.SetMulti(map[string]int{"a": 1, "b": 2})
+	c.SetMulti(map[string]int{"c": 3}, cache.WithExpiration(time.Second))
+
+	got := c.GetMulti([]string{"a", "b", "c", "d"})
+	if len(got) != 3 || got["a"] != 1 || got["b"] != 2 || got["c"] != 3 {
+		t.Fatalf("want map[a:1 b:2 c:3] but got %v", got)
+	}
+
+	cache.SetNowFunc(now.Add(2 * time.Second))
+	got = c.GetMulti([]string{"a", "b", "c"})
+	if len(got) != 2 || got["a"] != 1 || got["b"] != 2 {
+		t.Fatalf("want the expired item to be omitted but got %v", got)
+	}
+
+	c.DeleteMulti([]string{"a", "c", "d"})
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "b" {
+		t.Fatalf("want [b] but got %v", keys)
+	}
+}
+
+func TestDefaultExpiration(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New(cache.WithDefaultExpiration[string, int](time.Minute))
+	c.Set("default", 1)
+	c.Set("explicit", 2, cache.WithExpiration(time.Hour))
+	c.Set("none", 3, cache.WithNoExpiration())
+
+	cache.SetNowFunc(now.Add(2 * time.Minute))
+	if _, ok := c.Get("default"); ok {
+		t.Fatal("want the default expiration to be applied")
+	}
+	if _, ok := c.Get("explicit"); !ok {
+		t.Fatal("want the explicit expiration to override the default")
+	}
+
+	cache.SetNowFunc(now.Add(2 * time.Hour))
+	if _, ok := c.Get("explicit"); ok {
+		t.Fatal("want the explicit expiration to be applied")
+	}
+	if _, ok := c.Get("none"); !ok {
+		t.Fatal("want the item w/o expiration to be kept")
+	}
+}
+
+func TestExpirationOptionsLastWins(t *testing.T) {
+	c := cache.New(cache.WithDefaultExpiration[string, int](time.Minute))
+	c.Set("none", 1, cache.WithExpiration(time.Hour), cache.WithNoExpiration())
+	c.Set("hour", 2, cache.WithNoExpiration(), cache.WithExpiration(time.Hour))
+	c.Set("sliding", 3, cache.WithNoExpiration(), cache.WithSlidingExpiration(time.Hour))
+
+	if d, _ := c.TTL("none"); d != cache.NoExpiration {
+		t.Fatalf("want no expiration but got %v", d)
+	}
+	for _, key := range []string{"hour", "sliding"} {
+		if d, _ := c.TTL(key); d <= time.Minute || d > time.Hour {
+			t.Fatalf("want the expiration of %q to be 1h but got %v", key, d)
+		}
+	}
+}
+
+func TestExpirationJitter(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+	offsets := []int64{0, 20, 10}
+	resetJitter := cache.SetJitterFunc(func(n int64) int64 {
+		if n != int64(20*time.Second)+1 {
+			t.Fatalf("want the range of ±10s but got %d", n)
+		}
+		off := offsets[0]
+		offsets = offsets[1:]
+		return off * int64(time.Second)
+	})
+	defer resetJitter()
+
+	c := cache.New(cache.WithDefaultExpiration[string, int](time.Hour))
+	jitter := cache.WithExpirationJitter(time.Minute, 10*time.Second)
+	c.Set("a", 1, jitter)
+	c.Set("b", 2, jitter)
+	// spreads the default expiration.
+	c.Set("c", 3, cache.WithExpirationJitter(0, 10*time.Second))
+
+	want := map[string]time.Time{
+		"a": now.Add(50 * time.Second),
+		"b": now.Add(70 * time.Second),
+		"c": now.Add(time.Hour),
+	}
+	for key, exp := range want {
+		if _, got, _ := c.GetWithExpiration(key); !got.Equal(exp) {
+			t.Errorf("want %q to expire at %v but got %v", key, exp, got)
+		}
+	}
+
+	// the last expiration option wins.
+	c.Set("d", 4, jitter, cache.WithExpiration(time.Minute))
+	if _, got, _ := c.GetWithExpiration("d"); !got.Equal(now.Add(time.Minute)) {
+		t.Fatalf("want d to expire w/o jitter but got %v", got)
+	}
+}
+
+func TestJitterSource(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	expirations := func(seed int64) []time.Time {
+		c := cache.New(cache.WithJitterSource[int, int](rand.NewSource(seed)))
+		var exps []time.Time
+		for i := 0; i < 10; i++ {
+			c.Set(i, i, cache.WithExpirationJitter(time.Hour, time.Minute))
+			_, exp, _ := c.GetWithExpiration(i)
+			if d := exp.Sub(now); d < 59*time.Minute || d > 61*time.Minute {
+				t.Fatalf("want the expiration within 1h ± 1m but got %v", d)
+			}
+			exps = append(exps, exp)
+		}
+		return exps
+	}
+	if a, b := expirations(1), expirations(1); !reflect.DeepEqual(a, b) {
+		t.Fatalf("want the same expirations with the same seed but got %v and %v", a, b)
+	}
+
+	// a huge jitter is capped instead of overflowing.
+	c := cache.New[int, int]()
+	c.Set(1, 1, cache.WithExpirationJitter(time.Hour, time.Duration(math.MaxInt64)))
+	if !c.Contains(1) {
+		t.Fatal("want the item to be stored")
+	}
+}
+
+func TestSlidingExpiration(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.Set("sliding", 1, cache.WithSlidingExpiration(time.Minute))
+	c.Set("fixed", 2, cache.WithExpiration(time.Minute))
+
+	for i := 1; i <= 3; i++ {
+		cache.SetNowFunc(now.Add(time.Duration(i) * 50 * time.Second))
+		if _, ok := c.Get("sliding"); !ok {
+			t.Fatalf("want the sliding item to be renewed at %d", i)
+		}
+	}
+	if _, ok := c.Get("fixed"); ok {
+		t.Fatal("want the fixed item to be expired")
+	}
+
+	cache.SetNowFunc(now.Add(150*time.Second + 2*time.Minute))
+	if _, ok := c.Get("sliding"); ok {
+		t.Fatal("want the sliding item to be expired w/o access")
+	}
+}
+
+func TestResize(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(4)),
+		cache.WithEvictionCallback(func(k string, _ int) { evicted = append(evicted, k) }),
+	)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		c.Set(key, 0)
+	}
+	c.Get("a")
+
+	if got := c.Resize(2); got != 2 {
+		t.Fatalf("want 2 evictions but got %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "b,c" {
+		t.Fatalf("want b,c to be evicted but got %q", got)
+	}
+	if got := strings.Join(c.Keys(), ","); got != "d,a" {
+		t.Fatalf("want keys d,a but got %q", got)
+	}
+
+	if got := c.Resize(3); got != 0 {
+		t.Fatalf("want no eviction when growing but got %d", got)
+	}
+	c.Set("e", 0)
+	if got := c.Len(); got != 3 {
+		t.Fatalf("want 3 items after growing but got %d", got)
+	}
+}
+
+func TestResizeNegative(t *testing.T) {
+	for name, policy := range policies {
+		c := cache.New(policy)
+		for i := 0; i < 10; i++ {
+			c.Set(i, i)
+		}
+		if got := c.Resize(-1); got != 10 {
+			t.Errorf("%s: want 10 evictions but got %d", name, got)
+		}
+		if got := c.Len(); got != 0 {
+			t.Errorf("%s: want no items but got %d", name, got)
+		}
+	}
+}
+
+func TestEvictOldest(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.AsLRU[string, int](),
+		cache.WithStats[string, int](),
+		cache.WithEvictionCallback(func(k string, _ int) { evicted = append(evicted, k) }),
+	)
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(key, 0)
+	}
+	c.Get("a")
+
+	if key, _, ok := c.Oldest(); !ok || key != "b" {
+		t.Fatalf("want b to be the oldest but got %v %v", key, ok)
+	}
+	for c.Len() > 1 {
+		if _, _, ok := c.EvictOldest(); !ok {
+			t.Fatal("want an item to be evicted")
+		}
+	}
+	if got := strings.Join(evicted, ","); got != "b,c" {
+		t.Fatalf("want b,c to be evicted but got %q", got)
+	}
+	if got := c.Stats().CapacityEvictions; got != 2 {
+		t.Fatalf("want 2 evictions but got %d", got)
+	}
+	c.EvictOldest()
+	if _, _, ok := c.EvictOldest(); ok {
+		t.Fatal("want false for the empty cache")
+	}
+}
+
+func TestKeysOrder(t *testing.T) {
+	// the order of Keys documented by each policy after Set a, b, c and Get a.
+	// The LFU policy does not specify it.
+	tests := map[string]struct {
+		policy cache.Option[string, int]
+		want   string
+	}{
+		"simple":  {cache.AsSimple[string, int](), "a,b,c"},
+		"lru":     {cache.AsLRU[string, int](), "b,c,a"},
+		"fifo":    {cache.AsFIFO[string, int](), "a,b,c"},
+		"mru":     {cache.AsMRU[string, int](), "a,c,b"},
+		"clock":   {cache.AsClock[string, int](), "a,b,c"},
+		"tinylfu": {cache.AsTinyLFU[string, int](), "c,b,a"},
+		"2q":      {cache.As2Q[string, int](), "b,c,a"},
+		"arc":     {cache.AsARC[string, int](), "b,c,a"},
+		"lruk":    {cache.AsLRUK[string, int](2), "b,c,a"},
+		"slru":    {cache.AsSLRU[string, int](20, 80), "b,c,a"},
+	}
+	for name, tt := range tests {
+		c := cache.New(tt.policy)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Set("c", 3)
+		c.Get("a")
+		if got := strings.Join(c.Keys(), ","); got != tt.want {
+			t.Errorf("%s: want keys %q but got %q", name, tt.want, got)
+		}
+	}
+}
+
+// policies is the options of all of the cache replacement policies with the
+// default capacity. Each option creates a new policy whenever it is applied.
+var policies = map[string]cache.Option[int, int]{
+	"simple":  cache.AsSimple[int, int](),
+	"lru":     cache.AsLRU[int, int](),
+	"lfu":     cache.AsLFU[int, int](),
+	"fifo":    cache.AsFIFO[int, int](),
+	"mru":     cache.AsMRU[int, int](),
+	"clock":   cache.AsClock[int, int](),
+	"tinylfu": cache.AsTinyLFU[int, int](),
+	"2q":      cache.As2Q[int, int](),
+	"arc":     cache.AsARC[int, int](),
+	"lruk":    cache.AsLRUK[int, int](2),
+	"slru":    cache.AsSLRU[int, int](20, 80),
+}
+
+func TestKeysInEvictionOrder(t *testing.T) {
+	for name, policy := range policies {
+		c := cache.New(policy)
+		for i := 0; i < 30; i++ {
+			c.Set(i, i)
+		}
+		// every key is accessed a different number of times.
+		for i := 0; i < 30; i++ {
+			for n := 0; n < i*7%30; n++ {
+				c.Get(i)
+			}
+		}
+
+		want := c.KeysInEvictionOrder()
+		if len(want) != c.Len() {
+			t.Errorf("%s: want %d keys but got %d", name, c.Len(), len(want))
+		}
+		var got []int
+		for {
+			key, _, ok := c.EvictOldest()
+			if !ok {
+				break
+			}
+			got = append(got, key)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: want the eviction order %v but got %v", name, want, got)
+		}
+	}
+}
+
+func TestResizeAllPolicies(t *testing.T) {
+	for name, policy := range policies {
+		c := cache.New(policy)
+		for i := 0; i < 100; i++ {
+			c.Set(i, i)
+			if i%3 == 0 {
+				c.Get(i)
+			}
+		}
+		want := c.Len() - 10
+		if got := c.Resize(10); got != want {
+			t.Errorf("%s: want %d evictions but got %d", name, want, got)
+		}
+		for i := 100; i < 200; i++ {
+			c.Set(i, i)
+		}
+		if got := c.Len(); got != 10 {
+			t.Errorf("%s: want 10 items after shrinking but got %d", name, got)
+		}
+		if got := len(c.Keys()); got != 10 {
+			t.Errorf("%s: want 10 keys after shrinking but got %d", name, got)
+		}
+	}
+}
+
+func TestEvictionCallbackOnRemoval(t *testing.T) {
+	var (
+		c      *cache.Cache[string, int]
+		closed []string
+	)
+	c = cache.New(
+		cache.AsFIFO[string, int](),
+		cache.WithEvictionOnClose[string, int](),
+		cache.WithEvictionCallback(func(key string, _ int) {
+			// the item has been removed when the callback is called.
+			if c.Contains(key) {
+				t.Errorf("want %q to be removed before the callback", key)
+			}
+			closed = append(closed, key)
+		}),
+	)
+	steps := []struct {
+		name   string
+		remove func()
+		want   string
+	}{
+		{"Delete", func() { c.Delete("a"); c.Delete("b") }, "a,b"},
+		{"Clear", func() { c.Clear() }, "a,b"},
+		{"Flush", c.Flush, "a,b"},
+		{"Close", func() { c.Close() }, "a,b"},
+	}
+	for _, step := range steps {
+		c.Set("a", 1)
+		c.Set("b", 2)
+		closed = nil
+		step.remove()
+		if got := strings.Join(closed, ","); got != step.want {
+			t.Errorf("%s: want the callback for %q but got %q", step.name, step.want, got)
+		}
+	}
+}
+
+func TestClear(t *testing.T) {
+	for name, policy := range policies {
+		evicted := 0
+		c := cache.New(policy, cache.WithEvictionCallback(func(int, int) { evicted++ }))
+		for i := 0; i < 50; i++ {
+			c.Set(i, i)
+			c.Get(i)
+		}
+		if got := c.Clear(); got != 50 || evicted != 50 {
+			t.Errorf("%s: want 50 cleared items but got %d, %d callbacks", name, got, evicted)
+		}
+		if got := c.Len(); got != 0 || len(c.Keys()) != 0 {
+			t.Errorf("%s: want an empty cache but got %d items", name, got)
+		}
+		for i := 0; i < 200; i++ {
+			c.Set(i, i)
+		}
+		if got := c.Len(); got != len(c.Keys()) {
+			t.Errorf("%s: want the policy to work after Clear, but got %d items and %d keys", name, got, len(c.Keys()))
+		}
+	}
+}
+
+func TestCost(t *testing.T) {
+	c := cache.New(cache.AsLFU[string, int](lfu.WithCapacity(2)))
+	c.Set("expensive", 1, cache.WithCost(10))
+	c.Set("cheap", 2)
+	c.Get("cheap")
+	c.Set("new", 3)
+	if !c.Contains("expensive") || c.Contains("cheap") {
+		t.Fatalf("want the cheap item to be evicted, but got %v", c.Keys())
+	}
+}
+
+func TestPolicyName(t *testing.T) {
+	cases := []struct {
+		want   string
+		policy cache.Option[int, int]
+	}{
+		{want: "simple", policy: cache.AsSimple[int, int]()},
+		{want: "lru", policy: cache.AsLRU[int, int]()},
+		{want: "lfu", policy: cache.AsLFU[int, int]()},
+		{want: "fifo", policy: cache.AsFIFO[int, int]()},
+		{want: "mru", policy: cache.AsMRU[int, int]()},
+		{want: "clock", policy: cache.AsClock[int, int]()},
+		{want: "tinylfu", policy: cache.AsTinyLFU[int, int]()},
+		{want: "2q", policy: cache.As2Q[int, int]()},
+		{want: "arc", policy: cache.AsARC[int, int]()},
+		{want: "lruk", policy: cache.AsLRUK[int, int](2)},
+		{want: "slru", policy: cache.AsSLRU[int, int](2, 8)},
+	}
+	for _, tc := range cases {
+		if got := cache.New(tc.policy).PolicyName(); got != tc.want {
+			t.Errorf("want %q but got %q", tc.want, got)
+		}
+	}
+	if got := cache.New[int, int]().PolicyName(); got != "simple" {
+		t.Errorf("want %q for default but got %q", "simple", got)
+	}
+}
+
+// lifo is a custom cache replacement policy which evicts the newest item.
+type lifo[K comparable, V any] struct {
+	cap   int
+	keys  []K
+	items map[K]V
+}
+
+func (l *lifo[K, V]) Get(key K) (V, bool) {
+	v, ok := l.items[key]
+	return v, ok
+}
+
+func (l *lifo[K, V]) Set(key K, val V) { l.SetWithEvicted(key, val) }
+
+func (l *lifo[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	if _, ok := l.items[key]; !ok && len(l.keys) == l.cap {
+		evictedKey = l.keys[len(l.keys)-1]
+		evictedVal, evicted = l.items[evictedKey], true
+		l.Delete(evictedKey)
+	}
+	if _, ok := l.items[key]; !ok {
+		l.keys = append(l.keys, key)
+	}
+	l.items[key] = val
+	return
+}
+
+func (l *lifo[K, V]) Keys() []K { return append([]K(nil), l.keys...) }
+
+func (l *lifo[K, V]) Delete(key K) {
+	if _, ok := l.items[key]; !ok {
+		return
+	}
+	delete(l.items, key)
+	for i, k := range l.keys {
+		if k == key {
+			l.keys = append(l.keys[:i], l.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (l *lifo[K, V]) Len() int { return len(l.items) }
+
+func TestWithPolicy(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.WithPolicy[string, int](&lifo[string, *cache.Item[string, int]]{
+			cap:   2,
+			items: map[string]*cache.Item[string, int]{},
+		}),
+		cache.WithEvictionCallback(func(k string, _ int) { evicted = append(evicted, k) }),
+	)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	if got := strings.Join(c.Keys(), ","); got != "a,c" {
+		t.Fatalf("want keys a,c but got %q", got)
+	}
+	if got := strings.Join(evicted, ","); got != "b" {
+		t.Fatalf("want b to be reported as evicted but got %q", got)
+	}
+	if got, ok := c.Get("c"); !ok || got != 3 {
+		t.Fatalf("want c 3 but got %v %v", got, ok)
+	}
+	if got := c.PolicyName(); got != "unknown" {
+		t.Fatalf("want unknown policy name but got %q", got)
+	}
+}
+
+func TestDeleteExpiredN(t *testing.T) {
+	c := cache.New(cache.AsFIFO[int, int]())
+	for i := 0; i < 5; i++ {
+		c.Set(i, i, cache.WithExpiration(-time.Second))
+	}
+	c.Set(5, 5)
+
+	reaped, done := c.DeleteExpiredN(2)
+	if reaped != 2 || done {
+		t.Fatalf("want 2 false but got %d %v", reaped, done)
+	}
+	reaped, done = c.DeleteExpiredN(2)
+	if reaped != 2 || done {
+		t.Fatalf("want 2 false but got %d %v", reaped, done)
+	}
+	reaped, done = c.DeleteExpiredN(10)
+	if reaped != 1 || !done {
+		t.Fatalf("want 1 true but got %d %v", reaped, done)
+	}
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != 5 {
+		t.Fatalf("want [5] but got %v", keys)
+	}
+}
+
+func TestDeleteExpiredNAfterBumpEpoch(t *testing.T) {
+	c := cache.New(cache.AsFIFO[int, int]())
+	for i := 0; i < 3; i++ {
+		c.Set(i, i)
+	}
+	if reaped, done := c.DeleteExpiredN(10); reaped != 0 || !done {
+		t.Fatalf("want 0 true w/o expired items but got %d %v", reaped, done)
+	}
+
+	c.BumpEpoch()
+	reaped, done := c.DeleteExpiredN(2)
+	if reaped != 2 || done {
+		t.Fatalf("want 2 false but got %d %v", reaped, done)
+	}
+	reaped, done = c.DeleteExpiredN(2)
+	if reaped != 1 || !done {
+		t.Fatalf("want 1 true but got %d %v", reaped, done)
+	}
+	if got := c.Len(); got != 0 {
+		t.Fatalf("want no items but got %d", got)
+	}
+}
+
+func TestIncrementCallback(t *testing.T) {
+	type change struct {
+		key             string
+		delta, newValue int
+	}
+	var got []change
+	var nc *cache.NumberCache[string, int]
+	nc = cache.NewNumber(
+		cache.WithIncrementCallback(func(key string, delta, newValue int) {
+			// must not deadlock even if the callback calls back into the cache.
+			_, _ = nc.Get(key)
+			got = append(got, change{key, delta, newValue})
+		}),
+	)
+	nc.Increment("a", 3)
+	nc.Decrement("a", 1)
+
+	want := []change{{"a", 3, 3}, {"a", -1, 2}}
+	if len(got) != len(want) {
+		t.Fatalf("want %v but got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %v but got %v", want[i], got[i])
+		}
+	}
+}
+
+func TestIncrementFloat(t *testing.T) {
+	nc := cache.NewNumber[string, float64]()
+	for i := 0; i < 10; i++ {
+		nc.Increment("sum", 0.1)
+	}
+	got, _ := nc.Get("sum")
+	if math.Abs(got-1) > 1e-9 {
+		t.Fatalf("want about 1 but got %v", got)
+	}
+	if got := nc.Decrement("sum", 0.25); math.Abs(got-0.75) > 1e-9 {
+		t.Fatalf("want about 0.75 but got %v", got)
+	}
+
+	cc := cache.NewNumber[string, complex128]()
+	cc.Increment("z", 1+2i)
+	if got := cc.Increment("z", 0.5i); got != 1+2.5i {
+		t.Fatalf("want 1+2.5i but got %v", got)
+	}
+}
+
+func TestIncrementExisting(t *testing.T) {
+	nc := cache.NewNumber[string, int]()
+	if got, ok := nc.IncrementExisting("missing", 1); ok || got != 0 {
+		t.Fatalf("want 0 false for the missing key but got %v %v", got, ok)
+	}
+	if nc.Contains("missing") {
+		t.Fatal("want no item to be created for the missing key")
+	}
+
+	nc.Set("counter", 10)
+	if got, ok := nc.IncrementExisting("counter", 5); !ok || got != 15 {
+		t.Fatalf("want 15 true but got %v %v", got, ok)
+	}
+	if got, ok := nc.DecrementExisting("counter", 3); !ok || got != 12 {
+		t.Fatalf("want 12 true but got %v %v", got, ok)
+	}
+
+	nc.Set("expired", 1, cache.WithExpiration(-time.Second))
+	if _, ok := nc.DecrementExisting("expired", 1); ok {
+		t.Fatal("want false for the expired key")
+	}
+}
+
+func TestIncrementKeepsExpiration(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	nc := cache.NewNumber(cache.WithDefaultExpiration[string, int](time.Hour))
+	nc.Set("a", 1, cache.WithExpiration(time.Minute))
+	nc.Set("b", 1, cache.WithNoExpiration())
+
+	cache.SetNowFunc(now.Add(30 * time.Second))
+	nc.Increment("a", 1)
+	nc.Decrement("b", 1)
+	nc.Increment("c", 1)
+
+	if got, ok := nc.TTL("a"); !ok || got != 30*time.Second {
+		t.Fatalf("want the expiration of a to be kept, but got %v, %v", got, ok)
+	}
+	if got, ok := nc.TTL("b"); !ok || got != cache.NoExpiration {
+		t.Fatalf("want b w/o expiration, but got %v, %v", got, ok)
+	}
+	if got, ok := nc.TTL("c"); !ok || got != time.Hour {
+		t.Fatalf("want the default expiration for a new key, but got %v, %v", got, ok)
+	}
+}
+
+func TestIncrementBounded(t *testing.T) {
+	nc := cache.NewNumber[string, uint]()
+	var wg sync.WaitGroup
+	var applied int64
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok := cache.IncrementBounded(nc, "a", 1, 10); ok {
+				atomic.AddInt64(&applied, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got, _ := nc.Get("a"); got != 10 || applied != 10 {
+		t.Fatalf("want the counter to stop at 10, but got %d with %d increments", got, applied)
+	}
+	if got, ok := cache.IncrementBounded(nc, "a", 1, 10); ok || got != 10 {
+		t.Fatalf("want 10 false but got %d %v", got, ok)
+	}
+
+	if got, ok := cache.DecrementBounded(nc, "a", 7, 2); !ok || got != 3 {
+		t.Fatalf("want 3 true but got %d %v", got, ok)
+	}
+	if got, ok := cache.DecrementBounded(nc, "a", 2, 2); ok || got != 3 {
+		t.Fatalf("want 3 false but got %d %v", got, ok)
+	}
+	// the bound is checked w/o wrapping around.
+	if got, ok := cache.IncrementBounded(nc, "a", ^uint(0), ^uint(0)); ok || got != 3 {
+		t.Fatalf("want 3 false but got %d %v", got, ok)
+	}
+}
+
+func TestIncrementNotStored(t *testing.T) {
+	var notified int
+	nc := cache.NewNumber(
+		cache.AsLRU[string, int](lru.WithCapacity(1)),
+		cache.WithOverflowPolicy[string, int](cache.OverflowReject),
+		cache.WithIncrementCallback(func(string, int, int) { notified++ }),
+	)
+	nc.Set("a", 1)
+
+	// the cache is full, so a new key is rejected.
+	if got := nc.Increment("b", 1); got != 0 || nc.Contains("b") {
+		t.Fatalf("want 0 w/o storing b but got %d", got)
+	}
+	if got, ok := cache.IncrementBounded(nc, "b", 1, 10); ok || got != 0 {
+		t.Fatalf("want 0 false but got %d %v", got, ok)
+	}
+	if got, ok := nc.GetOrSet("b", 2); ok || got != 0 {
+		t.Fatalf("want 0 false but got %d %v", got, ok)
+	}
+
+	nc.Freeze()
+	if got := nc.Increment("a", 1); got != 1 {
+		t.Fatalf("want the current value 1 but got %d", got)
+	}
+	if got, ok := nc.IncrementExisting("a", 1); ok || got != 1 {
+		t.Fatalf("want 1 false but got %d %v", got, ok)
+	}
+	if got, ok := nc.Swap("a", 5); ok || got != 0 {
+		t.Fatalf("want 0 false but got %d %v", got, ok)
+	}
+	nc.Unfreeze()
+
+	if got, _ := nc.Get("a"); got != 1 {
+		t.Fatalf("want a to be kept as 1 but got %d", got)
+	}
+	if notified != 0 {
+		t.Fatalf("want no increment callback but got %d calls", notified)
+	}
+}
+
+func TestIncrementBoundedSigned(t *testing.T) {
+	nc := cache.NewNumber[string, int8]()
+	for _, tc := range []struct {
+		name      string
+		got       int8
+		decrement bool
+		n, bound  int8
+		want      int8
+		ok        bool
+	}{
+		{"increment from negative", -100, false, 50, 100, -50, true},
+		{"increment across zero", -100, false, 127, 100, 27, true},
+		{"increment over negative max", -120, false, 100, -100, -120, false},
+		{"increment to max", 90, false, 10, 100, 100, true},
+		{"increment over max", 90, false, 11, 100, 90, false},
+		{"decrement from positive", 100, true, 50, -100, 50, true},
+		{"decrement across zero", 100, true, 127, -100, -27, true},
+		{"decrement under positive min", 120, true, 100, 100, 120, false},
+		{"decrement under min", -90, true, 11, -100, -90, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			nc.Set("a", tc.got)
+			var got int8
+			var ok bool
+			if tc.decrement {
+				got, ok = cache.DecrementBounded(nc, "a", tc.n, tc.bound)
+			} else {
+				got, ok = cache.IncrementBounded(nc, "a", tc.n, tc.bound)
+			}
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("want %d %v but got %d %v", tc.want, tc.ok, got, ok)
+			}
+		})
+	}
+
+	ic := cache.NewNumber[string, int]()
+	ic.Set("a", math.MinInt)
+	if got, ok := cache.IncrementBounded(ic, "a", 1, math.MaxInt); !ok || got != math.MinInt+1 {
+		t.Errorf("want %d true but got %d %v", math.MinInt+1, got, ok)
+	}
+	ic.Set("a", math.MaxInt)
+	if got, ok := cache.DecrementBounded(ic, "a", 1, math.MinInt); !ok || got != math.MaxInt-1 {
+		t.Errorf("want %d true but got %d %v", math.MaxInt-1, got, ok)
+	}
+}
+
+func TestExpiryAwareEviction(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int](
+		lru.WithCapacity(2),
+		lru.WithExpiryAwareEviction(),
+	))
+	c.Set("a", 1, cache.WithExpiration(time.Hour))
+	c.Set("b", 2, cache.WithExpiration(time.Minute))
+	c.Get("b")
+	c.Set("c", 3)
+
+	if c.Contains("b") {
+		t.Fatal("want b which expires first to be evicted")
+	}
+	if !c.Contains("a") || !c.Contains("c") {
+		t.Fatalf("want a and c to be retained but got %v", c.Keys())
+	}
+}
+
+func TestRemoveOldest(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.AsFIFO[string, int](),
+		cache.WithEvictionCallback(func(key string, _ int) { evicted = append(evicted, key) }),
+	)
+	c.Set("a", 1, cache.WithExpiration(-time.Second))
+	c.Set("b", 2)
+	c.Set("c", 3)
+
+	if key, val, ok := c.Oldest(); !ok || key != "b" || val != 2 {
+		t.Fatalf("want b 2 but got %v %v %v", key, val, ok)
+	}
+	var drained []string
+	for {
+		key, _, ok := c.RemoveOldest()
+		if !ok {
+			break
+		}
+		drained = append(drained, key)
+	}
+	if got := strings.Join(drained, ","); got != "b,c" {
+		t.Fatalf("want b,c to be drained in order but got %q", got)
+	}
+	if got := strings.Join(evicted, ","); got != "a,b,c" {
+		t.Fatalf("want the eviction callback for all items but got %q", got)
+	}
+
+	if _, _, ok := cache.New[string, int]().Oldest(); ok {
+		t.Fatal("want false for the policy w/o Oldest")
+	}
+}
+
+func TestSwap(t *testing.T) {
+	c := cache.New[string, int]()
+	if prev, replaced := c.Swap("a", 1); replaced || prev != 0 {
+		t.Fatalf("want nothing to be replaced, but got %v %v", prev, replaced)
+	}
+	if prev, replaced := c.Swap("a", 2); !replaced || prev != 1 {
+		t.Fatalf("want 1 true but got %v %v", prev, replaced)
+	}
+	c.Set("b", 1, cache.WithExpiration(-time.Second))
+	if prev, replaced := c.Swap("b", 2); replaced || prev != 0 {
+		t.Fatalf("want the expired item not to be reported, but got %v %v", prev, replaced)
+	}
+	if got, _ := c.Get("b"); got != 2 {
+		t.Fatalf("want 2 but got %v", got)
+	}
+}
+
+func TestCompareAndDelete(t *testing.T) {
+	c := cache.New[string, string]()
+	c.Set("lock", "owner-1")
+	c.Set("lock", "owner-2")
+	c.Set("expired", "owner-1", cache.WithExpiration(-time.Second))
+
+	if c.CompareAndDelete("lock", "owner-1") {
+		t.Fatal("want the lock of another owner not to be deleted")
+	}
+	if c.CompareAndDelete("expired", "owner-1") {
+		t.Fatal("want the expired item not to be deleted")
+	}
+	if c.CompareAndDelete("missing", "") {
+		t.Fatal("want false for the missing key")
+	}
+	if !c.CompareAndDelete("lock", "owner-2") {
+		t.Fatal("want the lock to be deleted by the owner")
+	}
+	if c.Contains("lock") {
+		t.Fatal("want the lock to be deleted")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	c := cache.New[string, int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := []string{"a", "b"}[i%2]
+			c.Update(key, func(old int, exists bool) (int, bool) {
+				return old + 1, true
+			})
+		}(i)
+	}
+	wg.Wait()
+	if a, _ := c.Get("a"); a != 50 {
+		t.Fatalf("want no lost update, but got %d", a)
+	}
+
+	c.Update("a", func(old int, exists bool) (int, bool) {
+		// may call back into the cache for other keys.
+		c.Update("b", func(int, bool) (int, bool) { return 0, true })
+		return old, false
+	})
+	if c.Contains("a") {
+		t.Fatal("want a to be deleted")
+	}
+	if b, _ := c.Get("b"); b != 0 {
+		t.Fatalf("want b to be updated to 0, but got %d", b)
+	}
+
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+	c.Set("c", 1, cache.WithExpiration(time.Minute))
+	c.Update("c", func(old int, exists bool) (int, bool) { return old + 1, exists })
+	if d, ok := c.TTL("c"); !ok || d != time.Minute {
+		t.Fatalf("want the expiration to be kept, but got %v %v", d, ok)
+	}
+}
+
+func TestAdmissionFilter(t *testing.T) {
+	c := cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(2)),
+		cache.WithAdmissionFilter[string, int](100, 0.01),
+	)
+	if err := c.TrySet("a", 1); err != cache.ErrNotAdmitted {
+		t.Fatalf("want ErrNotAdmitted but got %v", err)
+	}
+	if c.Contains("a") {
+		t.Fatal("want the first Set not to be admitted")
+	}
+	if got, loaded := c.GetOrSet("z", 1); loaded || got != 0 {
+		t.Fatalf("want 0 false for the key not admitted but got %v %v", got, loaded)
+	}
+	c.Set("a", 2)
+	if got, ok := c.Get("a"); !ok || got != 2 {
+		t.Fatalf("want the second Set to be admitted, but got %v %v", got, ok)
+	}
+	c.Set("a", 3)
+	if got, _ := c.Get("a"); got != 3 {
+		t.Fatalf("want the existing item to be replaced, but got %v", got)
+	}
+
+	// a scan of one-hit-wonders does not flush the cache.
+	for i := 0; i < 10; i++ {
+		c.Set(string(rune('b'+i)), i)
+	}
+	if !c.Contains("a") || c.Len() != 1 {
+		t.Fatalf("want only a to be cached, but got %v", c.Keys())
+	}
+
+	c.ResetAdmission()
+	c.Set("b", 1)
+	if c.Contains("b") {
+		t.Fatal("want b to be forgotten by ResetAdmission")
+	}
+}
+
+func TestUpdateField(t *testing.T) {
+	type counters struct {
+		hits, misses int
+	}
+	c := cache.New[string, counters]()
+	c.Set("a", counters{hits: 1})
+
+	if !c.UpdateField("a", func(v *counters) { v.hits++ }) {
+		t.Fatal("want true")
+	}
+	if got, _ := c.Get("a"); got.hits != 2 || got.misses != 0 {
+		t.Fatalf("want {2 0} but got %v", got)
+	}
+
+	called := false
+	if c.UpdateField("b", func(*counters) { called = true }) || called {
+		t.Fatal("want false and fn not to be called for a missing key")
+	}
+}
+
+func TestFrequencyBuckets(t *testing.T) {
+	c := cache.New(cache.AsLFU[string, int]())
+	c.Set("a", 1)
+	c.Get("a")
+	buckets, ok := c.FrequencyBuckets()
+	if !ok || buckets[2] != 1 {
+		t.Fatalf("want map[2:1] true but got %v %v", buckets, ok)
+	}
+
+	if _, ok := cache.New[string, int]().FrequencyBuckets(); ok {
+		t.Fatal("want false for the simple cache")
+	}
+}
+
+func TestGetWithCount(t *testing.T) {
+	c := cache.New(cache.WithAccessCounting[string, int]())
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("a")
+	if v, count, ok := c.GetWithCount("a"); v != 1 || count != 3 || !ok {
+		t.Fatalf("want 1 3 true but got %d %d %v", v, count, ok)
+	}
+
+	// reset by storing again
+	c.Set("a", 2)
+	if _, count, _ := c.GetWithCount("a"); count != 1 {
+		t.Fatalf("want 1 but got %d", count)
+	}
+
+	if _, count, ok := c.GetWithCount("b"); count != 0 || ok {
+		t.Fatalf("want 0 false but got %d %v", count, ok)
+	}
+
+	nc := cache.New[string, int]()
+	nc.Set("a", 1)
+	if _, count, ok := nc.GetWithCount("a"); count != 0 || !ok {
+		t.Fatalf("want 0 true without counting but got %d %v", count, ok)
+	}
+}
+
+func TestOverflowPolicy(t *testing.T) {
+	t.Run("reject", func(t *testing.T) {
+		c := cache.New(
+			cache.AsLRU[string, int](lru.WithCapacity(2)),
+			cache.WithOverflowPolicy[string, int](cache.OverflowReject),
+		)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		if err := c.TrySet("c", 3); err != cache.ErrCacheFull {
+			t.Fatalf("want ErrCacheFull but got %v", err)
+		}
+		if err := c.TrySet("a", 10); err != nil {
+			t.Fatalf("want replacing to succeed but got %v", err)
+		}
+		if c.Contains("c") || !c.Contains("b") {
+			t.Fatal("want existing items to be kept")
+		}
+	})
+
+	t.Run("rejected by the policy", func(t *testing.T) {
+		c := cache.New(
+			cache.AsSimple[string, int](simple.WithCapacity(2), simple.WithRejectWhenFull()),
+			cache.WithMaxBytes(100, func(string, int) int64 { return 10 }),
+		)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		if err := c.TrySet("c", 3); err != cache.ErrCacheFull {
+			t.Fatalf("want ErrCacheFull but got %v", err)
+		}
+		if got, loaded := c.GetOrSet("d", 4); loaded || got != 0 {
+			t.Fatalf("want 0 false but got %d %v", got, loaded)
+		}
+		if got := c.Bytes(); got != 20 {
+			t.Fatalf("want 20 bytes but got %d", got)
+		}
+		if got := strings.Join(c.Keys(), ","); got != "a,b" {
+			t.Fatalf("want keys a,b but got %q", got)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		c := cache.New(
+			cache.AsFIFO[string, int](fifo.WithCapacity(1)),
+			cache.WithOverflowPolicy[string, int](cache.OverflowBlock),
+			cache.WithOverflowTimeout[string, int](time.Second),
+		)
+		c.Set("a", 1)
+
+		errc := make(chan error, 1)
+		go func() { errc <- c.TrySet("b", 2) }()
+
+		time.Sleep(10 * time.Millisecond)
+		c.Delete("a")
+
+		if err := <-errc; err != nil {
+			t.Fatalf("want nil but got %v", err)
+		}
+		if got, ok := c.Get("b"); !ok || got != 2 {
+			t.Fatalf("want 2 true but got %d %v", got, ok)
+		}
+	})
+
+	t.Run("block timeout", func(t *testing.T) {
+		c := cache.New(
+			cache.AsFIFO[string, int](fifo.WithCapacity(1)),
+			cache.WithOverflowPolicy[string, int](cache.OverflowBlock),
+			cache.WithOverflowTimeout[string, int](10*time.Millisecond),
+		)
+		c.Set("a", 1)
+		if err := c.TrySet("b", 2); err != cache.ErrCacheFull {
+			t.Fatalf("want ErrCacheFull but got %v", err)
+		}
+	})
+}
+
+func TestGetOrSet(t *testing.T) {
+	c := cache.New[string, int]()
+	if actual, loaded := c.GetOrSet("a", 1); actual != 1 || loaded {
+		t.Fatalf("want 1 false but got %d %v", actual, loaded)
+	}
+	if actual, loaded := c.GetOrSet("a", 2); actual != 1 || !loaded {
+		t.Fatalf("want 1 true but got %d %v", actual, loaded)
+	}
+
+	c.Set("b", 1, cache.WithExpiration(-time.Second))
+	if actual, loaded := c.GetOrSet("b", 2); actual != 2 || loaded {
+		t.Fatalf("want expired item to be overwritten, but got %d %v", actual, loaded)
+	}
+	if got, _ := c.Get("b"); got != 2 {
+		t.Fatalf("want 2 but got %d", got)
+	}
+
+	var wg sync.WaitGroup
+	var stored int64
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, loaded := c.GetOrSet("c", i); !loaded {
+				atomic.AddInt64(&stored, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if stored != 1 {
+		t.Fatalf("want only one goroutine to store but got %d", stored)
+	}
+}
+
+func TestGetAndDelete(t *testing.T) {
+	c := cache.New[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(-time.Second))
+
+	if got, ok := c.GetAndDelete("a"); got != 1 || !ok {
+		t.Fatalf("want 1 true but got %d %v", got, ok)
+	}
+	if c.Contains("a") {
+		t.Fatal("want a to be deleted")
+	}
+	if _, ok := c.GetAndDelete("a"); ok {
+		t.Fatal("want false for the deleted key")
+	}
+	if _, ok := c.GetAndDelete("b"); ok {
+		t.Fatal("want false for the expired key")
+	}
+	if len(c.Keys()) != 0 {
+		t.Fatalf("want the expired item to be deleted but got %v", c.Keys())
+	}
+}
+
+func TestTTL(t *testing.T) {
+	reset := cache.SetNowFunc(time.Now())
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	c.Set("b", 2)
+	c.Set("c", 3, cache.WithExpiration(-time.Second))
+
+	if d, ok := c.TTL("a"); d != time.Minute || !ok {
+		t.Errorf("want %v true but got %v %v", time.Minute, d, ok)
+	}
+	if d, ok := c.TTL("b"); d != cache.NoExpiration || !ok {
+		t.Errorf("want NoExpiration true but got %v %v", d, ok)
+	}
+	if _, ok := c.TTL("c"); ok {
+		t.Error("want false for the expired key")
+	}
+	if _, ok := c.TTL("d"); ok {
+		t.Error("want false for the missing key")
+	}
+}
+
+func TestEvictionCallback(t *testing.T) {
+	type evicted struct {
+		key string
+		val int
+	}
+	var got []evicted
+	var c *cache.Cache[string, int]
+	c = cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(2)),
+		cache.WithEvictionCallback(func(key string, val int) {
+			// must not deadlock even if the callback calls back into the cache.
+			_ = c.Contains(key)
+			got = append(got, evicted{key, val})
+		}),
+	)
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("b", 20) // replacing is not an eviction
+	c.Set("c", 3)  // evicts a
+	c.Delete("b")
+	c.Set("d", 4, cache.WithExpiration(-time.Second))
+	c.DeleteExpired()
+	c.Flush()
+
+	want := []evicted{{"a", 1}, {"b", 20}, {"d", 4}, {"c", 3}}
+	if len(got) != len(want) {
+		t.Fatalf("want %v but got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("want %v but got %v", want[i], got[i])
+		}
+	}
+}
+
+func TestExpiredCallback(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	var got []string
+	var c *cache.Cache[string, int]
+	c = cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(2)),
+		cache.WithExpiredCallback(func(key string, val int) {
+			// must not deadlock even if the callback re-sets the key.
+			c.Set(key, val+1)
+			got = append(got, key)
+		}),
+	)
+
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	c.Set("b", 2)
+	c.Set("c", 3) // evicts a, which is not an expiration
+	c.Delete("b")
+	c.Set("d", 4, cache.WithExpiration(time.Minute))
+
+	cache.SetNowFunc(now.Add(2 * time.Minute))
+	c.DeleteExpired()
+	if len(got) != 1 || got[0] != "d" {
+		t.Fatalf("want only d to be expired, but got %v", got)
+	}
+	if v, ok := c.Get("d"); !ok || v != 5 {
+		t.Fatalf("want d to be re-set by the callback, but got %v, %v", v, ok)
+	}
+}
+
+func TestLen(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(2)))
+	if got := c.Len(); got != 0 {
+		t.Fatalf("want 0 but got %d", got)
+	}
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(-time.Second))
+	// expired items are counted until deleted
+	if got := c.Len(); got != 2 {
+		t.Fatalf("want 2 but got %d", got)
+	}
+	c.Set("c", 3)
+	if got := c.Len(); got != 2 {
+		t.Fatalf("want 2 over the cap but got %d", got)
+	}
+	c.DeleteExpired()
+	if got := c.Len(); got != 1 {
+		t.Fatalf("want 1 after deleting expired items but got %d", got)
+	}
+}
+
+func TestGetDoesNotPromoteExpiredItems(t *testing.T) {
+	for name, policy := range map[string]cache.Option[string, int]{
+		"lru":   cache.AsLRU[string, int](lru.WithCapacity(2)),
+		"clock": cache.AsClock[string, int](clock.WithCapacity(2)),
+	} {
+		c := cache.New(policy)
+		c.Set("expired", 1, cache.WithExpiration(-time.Second))
+		c.Set("live", 2)
+		for i := 0; i < 3; i++ {
+			c.Get("expired")
+		}
+		c.Set("new", 3)
+		if !c.Contains("live") {
+			t.Errorf("%s: want the expired item to be evicted instead of the live one, but got %v", name, c.Keys())
+		}
+	}
+}
+
+func TestKeysSnapshot(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(2)))
+	c.Set("a", 1)
+	keys, gen := c.KeysSnapshot()
+	if len(keys) != 1 || keys[0] != "a" {
+		t.Fatalf("want [a] but got %v", keys)
+	}
+
+	c.Get("a")
+	c.Peek("a")
+	if got := c.Generation(); got != gen {
+		t.Fatalf("want the generation not to be advanced by reads, but got %d -> %d", gen, got)
+	}
+	for _, mutate := range []func(){
+		func() { c.Set("b", 2) },
+		func() { c.Set("c", 3) }, // evicts
+		func() { c.Delete("b") },
+		func() { c.UpdateField("c", func(v *int) { *v++ }) },
+		func() { c.Clear() },
+	} {
+		mutate()
+		got := c.Generation()
+		if got == gen {
+			t.Fatalf("want the generation to be advanced, but got %d", got)
+		}
+		gen = got
+	}
+}
+
+func TestDeleteExpiredDoesNotReferenceItems(t *testing.T) {
+	c := cache.New(cache.AsLFU[string, int]())
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(-time.Second))
+	c.DeleteExpired()
+	buckets, _ := c.FrequencyBuckets()
+	if len(buckets) != 1 || buckets[1] != 1 {
+		t.Fatalf("want the reference count of a to be kept, but got %v", buckets)
+	}
+}
+
+func TestDeleteExpiredDuringPass(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	var c *cache.Cache[string, int]
+	c = cache.New(cache.WithExpiredCallback(func(key string, _ int) {
+		// set during the pass, examined by the next one.
+		c.Set(key+"'", 0, cache.WithExpiration(-time.Second))
+	}))
+	c.Set("a", 1, cache.WithExpiration(-time.Second))
+
+	c.DeleteExpired()
+	if got := c.Keys(); len(got) != 1 || got[0] != "a'" {
+		t.Fatalf("want the item set during the pass to be kept, but got %v", got)
+	}
+	c.DeleteExpired()
+	if got := c.Len(); got != 1 {
+		t.Fatalf("want the next pass to delete a' and set a'', but got %d items", got)
+	}
+}
+
+func TestLiveLen(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New(cache.AsLRU[string, int]())
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	c.Set("b", 2, cache.WithExpiration(time.Hour))
+	c.Set("c", 3)
+
+	cache.SetNowFunc(now.Add(2 * time.Minute))
+	if got := c.LiveLen(); got != 2 {
+		t.Fatalf("want 2 but got %d", got)
+	}
+	if got := c.Len(); got != 3 {
+		t.Fatalf("want Len to count the expired item, but got %d", got)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	c := cache.New[string, int]()
+	if c.Replace("a", 1) {
+		t.Fatal("want false for the missing key")
+	}
+	if c.Contains("a") {
+		t.Fatal("want the missing key not to be stored")
+	}
+
+	c.Set("a", 1)
+	if !c.Replace("a", 2) {
+		t.Fatal("want true for the existing key")
+	}
+	if got, _ := c.Get("a"); got != 2 {
+		t.Fatalf("want 2 but got %d", got)
+	}
+
+	c.Set("b", 1, cache.WithExpiration(-time.Second))
+	if c.Replace("b", 2) {
+		t.Fatal("want false for the expired key")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	c := cache.New[string, int]()
+	if !c.Add("a", 1) {
+		t.Fatal("want true for the missing key")
+	}
+	if c.Add("a", 2) {
+		t.Fatal("want false for the existing key")
+	}
+	if got, _ := c.Get("a"); got != 1 {
+		t.Fatalf("want 1 but got %d", got)
+	}
+
+	c.Set("b", 1, cache.WithExpiration(-time.Second))
+	if !c.Add("b", 2) {
+		t.Fatal("want true for the expired key")
+	}
+	if got, _ := c.Get("b"); got != 2 {
+		t.Fatalf("want 2 but got %d", got)
+	}
+}
+
+func TestSetNX(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, string]()
+	if !c.SetNX("lock", "a", time.Second) {
+		t.Fatal("want the lock to be acquired")
+	}
+	if c.SetNX("lock", "b", time.Second) {
+		t.Fatal("want the held lock not to be acquired")
+	}
+	if _, exp, _ := c.GetWithExpiration("lock"); !exp.Equal(now.Add(time.Second)) {
+		t.Fatalf("want the lock to expire in a second but got %v", exp)
+	}
+
+	cache.SetNowFunc(now.Add(2 * time.Second))
+	if !c.SetNX("lock", "b", time.Second) {
+		t.Fatal("want the expired lock to be acquired")
+	}
+	if got, _ := c.Get("lock"); got != "b" {
+		t.Fatalf("want the lock to be held by b but got %q", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	c := cache.New(cache.AsFIFO[string, int]())
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(-time.Second))
+	c.Set("c", 3)
+	c.Set("d", 4)
+
+	var keys []string
+	c.Range(func(key string, val int) bool {
+		keys = append(keys, key)
+		return key != "c"
+	})
+	if got := strings.Join(keys, ","); got != "a,c" {
+		t.Fatalf("want %q but got %q", "a,c", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := cache.New(
+		cache.AsLRU[string, int](lru.WithCapacity(2)),
+		cache.WithStats[string, int](),
+	)
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(-time.Second))
+	c.Get("a")
+	c.Get("b") // expired
+	c.Get("c")
+	c.DeleteExpired()
+	c.Set("c", 3)
+	c.Set("d", 4) // evicts a
+	c.Delete("c")
+	c.Delete("missing")
+	c.Clear() // deletes d
+
+	want := cache.Stats{Hits: 1, Misses: 2, CapacityEvictions: 1, Evictions: 1, Expirations: 1, ManualDeletes: 2}
+	if got := c.Stats(); got != want {
+		t.Fatalf("want %+v but got %+v", want, got)
+	}
+
+	nc := cache.New[string, int]()
+	nc.Get("a")
+	if got := nc.Stats(); got != (cache.Stats{}) {
+		t.Fatalf("want zero stats without the option but got %+v", got)
+	}
+}
+
+func BenchmarkDeleteExpired(b *testing.B) {
+	c := cache.New(cache.AsLRU[int, int](lru.WithCapacity(100000)))
+	for i := 0; i < 100000; i++ {
+		c.Set(i, i)
+	}
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		// 10% of the items are expired.
+		for i := 0; i < 100000; i += 10 {
+			c.Set(i, i, cache.WithExpiration(-time.Second))
+		}
+		b.StartTimer()
+		c.DeleteExpired()
+	}
+}
@@ -0,0 +1,77 @@
+// Package cacheprom exports the statistics of caches as Prometheus metrics.
+//
+// It is a separate module from the cache, so that the cache does not depend
+// on the Prometheus client.
+package cacheprom
+
+import (
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Source is a cache whose statistics are exported. *cache.Cache is a Source.
+type Source interface {
+	Stats() cache.Stats
+	Len() int
+}
+
+// Collector is a prometheus.Collector which exports the statistics of a cache.
+//
+// The counters are read from Stats, which are zero unless the cache is created
+// with cache.WithStats. Collecting the metrics does not take the lock of the
+// cache except for Len.
+type Collector struct {
+	src               Source
+	hits              *prometheus.Desc
+	misses            *prometheus.Desc
+	capacityEvictions *prometheus.Desc
+	expirations       *prometheus.Desc
+	manualDeletes     *prometheus.Desc
+	entries           *prometheus.Desc
+	hitRatio          *prometheus.Desc
+}
+
+// NewCollector creates a new Collector of src. The metrics are labeled with
+// name, so that the collectors of several caches can be registered at once.
+func NewCollector(name string, src Source) *Collector {
+	labels := prometheus.Labels{"name": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName("cache", "", metric), help, nil, labels)
+	}
+	return &Collector{
+		src:               src,
+		hits:              desc("hits_total", "The number of lookups which found the key."),
+		misses:            desc("misses_total", "The number of lookups which did not find the key."),
+		capacityEvictions: desc("capacity_evictions_total", "The number of items evicted due to the capacity."),
+		expirations:       desc("expirations_total", "The number of expired items deleted."),
+		manualDeletes:     desc("manual_deletes_total", "The number of items deleted explicitly."),
+		entries:           desc("entries", "The number of items in the cache."),
+		hitRatio:          desc("hit_ratio", "The ratio of hits to all lookups."),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.capacityEvictions
+	ch <- c.expirations
+	ch <- c.manualDeletes
+	ch <- c.entries
+	ch <- c.hitRatio
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.src.Stats()
+	counter := func(desc *prometheus.Desc, v uint64) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(v))
+	}
+	counter(c.hits, stats.Hits)
+	counter(c.misses, stats.Misses)
+	counter(c.capacityEvictions, stats.CapacityEvictions)
+	counter(c.expirations, stats.Expirations)
+	counter(c.manualDeletes, stats.ManualDeletes)
+	ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(c.src.Len()))
+	ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, stats.HitRatio())
+}
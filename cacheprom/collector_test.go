@@ -0,0 +1,34 @@
+package cacheprom_test
+
+import (
+	"testing"
+
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/gekatateam/go-generics-cache/cacheprom"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	c := cache.New(cache.WithStats[string, int]())
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("b")
+
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(cacheprom.NewCollector("users", c)); err != nil {
+		t.Fatal(err)
+	}
+	// the collectors of several caches are told apart by the name label.
+	if err := reg.Register(cacheprom.NewCollector("posts", cache.New[string, int]())); err != nil {
+		t.Fatal(err)
+	}
+
+	collector := cacheprom.NewCollector("users", c)
+	if got := testutil.CollectAndCount(collector); got != 7 {
+		t.Fatalf("want 7 metrics but got %d", got)
+	}
+	if got := testutil.CollectAndCount(collector, "cache_hits_total"); got != 1 {
+		t.Fatalf("want the hit counter but got %d metrics", got)
+	}
+}
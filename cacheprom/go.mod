@@ -0,0 +1,10 @@
+module github.com/gekatateam/go-generics-cache/cacheprom
+
+go 1.18
+
+require (
+	github.com/gekatateam/go-generics-cache v0.0.0-00010101000000-000000000000
+	github.com/prometheus/client_golang v1.14.0
+)
+
+replace github.com/gekatateam/go-generics-cache => ../
@@ -2,7 +2,18 @@ package cache
 
 import "golang.org/x/exp/constraints"
 
-// Number is a constraint that permits any numeric types.
+// Number is a constraint that permits any numeric types, which are all of the
+// integer, floating-point and complex types including named types of them.
+//
+// Note that Increment of floating-point values accumulates rounding errors as
+// the + operator does. For example, adding 0.1 ten times to 0 does not result
+// in exactly 1. Use integers of a fixed unit, such as cents, if it matters.
 type Number interface {
 	constraints.Integer | constraints.Float | constraints.Complex
 }
+
+// Real is a constraint that permits any ordered numeric types. It excludes the
+// complex types of Number, which are not ordered, for bounded increments.
+type Real interface {
+	constraints.Integer | constraints.Float
+}
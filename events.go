@@ -0,0 +1,96 @@
+package cache
+
+// EventOp is the kind of a mutation of the cache.
+type EventOp int
+
+const (
+	// EventSet is published when an item is set.
+	EventSet EventOp = iota
+	// EventDelete is published when an item is deleted by Delete, Flush or the like.
+	EventDelete
+	// EventEvict is published when an item is evicted by the cache replacement policy.
+	EventEvict
+	// EventExpire is published when an expired item is deleted by the janitor.
+	EventExpire
+)
+
+// String returns the name of the operation.
+func (op EventOp) String() string {
+	switch op {
+	case EventSet:
+		return "set"
+	case EventDelete:
+		return "delete"
+	case EventEvict:
+		return "evict"
+	case EventExpire:
+		return "expire"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a mutation of the cache which is published to subscribers.
+type Event[K comparable, V any] struct {
+	Op    EventOp
+	Key   K
+	Value V
+}
+
+// eventBufferSize is the buffer size of the channel returned by Subscribe.
+const eventBufferSize = 128
+
+// Subscribe returns a channel which receives an event for each mutation of
+// the cache. Each subscriber has its own channel, so many consumers can watch
+// the cache independently.
+//
+// The channel is buffered, and events are dropped if the buffer is full, so a
+// slow subscriber never blocks cache operations. The number of dropped events
+// is reported by DroppedEvents. Call Unsubscribe to close the channel.
+func (c *Cache[K, V]) Subscribe() <-chan Event[K, V] {
+	ch := make(chan Event[K, V], eventBufferSize)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subscribers = append(c.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops the subscription and closes the channel returned by
+// Subscribe. It does nothing if the channel is not subscribed.
+func (c *Cache[K, V]) Unsubscribe(ch <-chan Event[K, V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, sub := range c.subscribers {
+		if sub == ch {
+			c.subscribers = append(c.subscribers[:i], c.subscribers[i+1:]...)
+			close(sub)
+			return
+		}
+	}
+}
+
+// DroppedEvents returns the number of events which have been dropped because
+// the buffer of the subscriber was full.
+func (c *Cache[K, V]) DroppedEvents() uint64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.droppedEvents
+}
+
+// publish advances the generation of the cache, and sends the event of the
+// item to each subscriber w/o blocking. The caller must hold the write lock.
+func (c *Cache[K, V]) publish(op EventOp, item *Item[K, V]) {
+	c.generation++
+	if len(c.subscribers) == 0 {
+		return
+	}
+	val, _ := item.load()
+	ev := Event[K, V]{Op: op, Key: item.Key, Value: val}
+	for _, sub := range c.subscribers {
+		select {
+		case sub <- ev:
+		default:
+			c.droppedEvents++
+		}
+	}
+}
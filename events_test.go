@@ -0,0 +1,63 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+)
+
+func TestSubscribe(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(2)))
+	events := c.Subscribe()
+	other := c.Subscribe()
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3) // evicts a
+	c.Delete("b")
+	c.Set("d", 4, cache.WithExpiration(-time.Second))
+	c.DeleteExpired()
+
+	want := []cache.Event[string, int]{
+		{Op: cache.EventSet, Key: "a", Value: 1},
+		{Op: cache.EventSet, Key: "b", Value: 2},
+		{Op: cache.EventSet, Key: "c", Value: 3},
+		{Op: cache.EventEvict, Key: "a", Value: 1},
+		{Op: cache.EventDelete, Key: "b", Value: 2},
+		{Op: cache.EventSet, Key: "d", Value: 4},
+		{Op: cache.EventExpire, Key: "d", Value: 4},
+	}
+	for _, ch := range []<-chan cache.Event[string, int]{events, other} {
+		for i, w := range want {
+			if got := <-ch; got != w {
+				t.Errorf("event %d: want %v but got %v", i, w, got)
+			}
+		}
+	}
+
+	c.Unsubscribe(events)
+	if _, ok := <-events; ok {
+		t.Fatal("want the channel to be closed")
+	}
+	c.Unsubscribe(events) // does nothing
+	c.Set("e", 5)
+	if got := <-other; got.Key != "e" {
+		t.Fatalf("want the other subscriber to keep receiving, but got %v", got)
+	}
+}
+
+func TestSubscribeDropsEvents(t *testing.T) {
+	c := cache.New[int, int]()
+	events := c.Subscribe()
+	for i := 0; i < 200; i++ {
+		c.Set(i, i)
+	}
+	if got := len(events); got != cap(events) {
+		t.Fatalf("want the buffer to be full, but got %d", got)
+	}
+	if got := c.DroppedEvents(); got != uint64(200-cap(events)) {
+		t.Fatalf("want %d dropped events but got %d", 200-cap(events), got)
+	}
+}
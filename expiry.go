@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"container/heap"
+	"time"
+)
+
+// expiryIndex is a min-heap of the keys of items with an expiration, ordered
+// by the expiration, so that DeleteExpired finds expired items w/o scanning
+// all of the items. The zero value is ready to use.
+type expiryIndex[K comparable] struct {
+	heap  expiryHeap[K]
+	index map[K]*expiryEntry[K]
+}
+
+type expiryEntry[K comparable] struct {
+	key K
+	at  time.Time
+	pos int
+}
+
+// set adds the key with the expiration at, or updates the expiration if the
+// key has been added.
+func (x *expiryIndex[K]) set(key K, at time.Time) {
+	if e, ok := x.index[key]; ok {
+		e.at = at
+		heap.Fix(&x.heap, e.pos)
+		return
+	}
+	if x.index == nil {
+		x.index = make(map[K]*expiryEntry[K])
+	}
+	e := &expiryEntry[K]{key: key, at: at}
+	x.index[key] = e
+	heap.Push(&x.heap, e)
+}
+
+// remove removes the key if it has been added.
+func (x *expiryIndex[K]) remove(key K) {
+	if e, ok := x.index[key]; ok {
+		heap.Remove(&x.heap, e.pos)
+		delete(x.index, key)
+	}
+}
+
+// popExpired removes at most n keys which expire at or before now, and
+// returns them from the earliest.
+func (x *expiryIndex[K]) popExpired(now time.Time, n int) []K {
+	var keys []K
+	for len(keys) < n && x.expired(now) {
+		e := heap.Pop(&x.heap).(*expiryEntry[K])
+		delete(x.index, e.key)
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// expired reports whether any key expires at or before now.
+func (x *expiryIndex[K]) expired(now time.Time) bool {
+	return len(x.heap) > 0 && !x.heap[0].at.After(now)
+}
+
+// len returns the number of the keys.
+func (x *expiryIndex[K]) len() int {
+	return len(x.heap)
+}
+
+// clear removes all of the keys.
+func (x *expiryIndex[K]) clear() {
+	x.heap = nil
+	x.index = nil
+}
+
+type expiryHeap[K comparable] []*expiryEntry[K]
+
+func (h expiryHeap[K]) Len() int { return len(h) }
+
+func (h expiryHeap[K]) Less(i, j int) bool { return h[i].at.Before(h[j].at) }
+
+func (h expiryHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+
+func (h *expiryHeap[K]) Push(x interface{}) {
+	e := x.(*expiryEntry[K])
+	e.pos = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *expiryHeap[K]) Pop() interface{} {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
@@ -9,3 +9,11 @@ func SetNowFunc(tm time.Time) (reset func()) {
 		nowFunc = backup
 	}
 }
+
+func SetJitterFunc(fn func(n int64) int64) (reset func()) {
+	backup := jitterFunc
+	jitterFunc = fn
+	return func() {
+		jitterFunc = backup
+	}
+}
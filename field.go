@@ -0,0 +1,63 @@
+package cache
+
+// SetField sets val to field of the map stored with key, like HSET of Redis.
+// The map is created on the first call for the key, and opts are applied to
+// it. Otherwise the expiration of the map is kept unless opts specify another.
+// This is done under the write lock of the cache.
+//
+// The map is copied on each change instead of being updated in place, so maps
+// returned by Get can be read while fields are changed concurrently. They must
+// not be modified by callers.
+func SetField[K, F comparable, V any](c *Cache[K, map[F]V], key K, field F, val V, opts ...ItemOption) {
+	c.mu.Lock()
+	defer c.unlock()
+	item, m, ok := c.peekItem(key)
+	fields := make(map[F]V, len(m)+1)
+	for f, v := range m {
+		fields[f] = v
+	}
+	fields[field] = val
+	if ok {
+		opts = append([]ItemOption{keepExpiration(item)}, opts...)
+	}
+	_ = c.store(key, fields, opts...)
+}
+
+// GetField looks up field of the map stored with key, like HGET of Redis.
+// ok is false if the key or field is not found, or the map has been expired.
+func GetField[K, F comparable, V any](c *Cache[K, map[F]V], key K, field F) (val V, ok bool) {
+	m, ok := c.Get(key)
+	if !ok {
+		return val, false
+	}
+	val, ok = m[field]
+	return val, ok
+}
+
+// DeleteField deletes field from the map stored with key, like HDEL of Redis,
+// and reports whether it has been deleted. The map is deleted from the cache
+// when the last field is deleted. The expiration of the map is kept.
+// This is done under the write lock of the cache.
+func DeleteField[K, F comparable, V any](c *Cache[K, map[F]V], key K, field F) bool {
+	c.mu.Lock()
+	defer c.unlock()
+	item, m, ok := c.peekItem(key)
+	if !ok || c.frozen {
+		return false
+	}
+	if _, ok := m[field]; !ok {
+		return false
+	}
+	if len(m) == 1 {
+		c.delete(key)
+		return true
+	}
+	fields := make(map[F]V, len(m)-1)
+	for f, v := range m {
+		if f != field {
+			fields[f] = v
+		}
+	}
+	c.set(key, fields, keepExpiration(item))
+	return true
+}
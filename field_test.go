@@ -0,0 +1,45 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestFields(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, map[string]int]()
+	cache.SetField(c, "user", "age", 30, cache.WithExpiration(time.Minute))
+	cache.SetField(c, "user", "score", 100)
+
+	if got, ok := cache.GetField(c, "user", "age"); !ok || got != 30 {
+		t.Fatalf("want age 30 but got %v %v", got, ok)
+	}
+	if _, ok := cache.GetField(c, "user", "name"); ok {
+		t.Fatal("want the missing field not to be found")
+	}
+	if _, exp, _ := c.GetWithExpiration("user"); !exp.Equal(now.Add(time.Minute)) {
+		t.Fatalf("want the expiration to be kept but got %v", exp)
+	}
+
+	// the map returned by Get is not changed by SetField.
+	m, _ := c.Get("user")
+	cache.SetField(c, "user", "age", 31)
+	if m["age"] != 30 {
+		t.Fatalf("want the returned map not to be modified but got %v", m)
+	}
+
+	if cache.DeleteField(c, "user", "name") {
+		t.Fatal("want false for the missing field")
+	}
+	if !cache.DeleteField(c, "user", "age") || !c.Contains("user") {
+		t.Fatal("want age to be deleted and user to be kept")
+	}
+	if !cache.DeleteField(c, "user", "score") || c.Contains("user") {
+		t.Fatal("want user to be deleted with the last field")
+	}
+}
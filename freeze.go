@@ -0,0 +1,43 @@
+package cache
+
+import "errors"
+
+// ErrFrozen is returned by TrySet when the value is not stored because the
+// cache is frozen by Freeze.
+var ErrFrozen = errors.New("cache: cache is frozen")
+
+// Freeze makes the contents of the cache immutable until Unfreeze is called,
+// so that a long series of reads sees stable contents w/o copying them like
+// Snapshot.
+//
+// While frozen, methods which modify the items do nothing: Set and the other
+// setters do not store values, and TrySet returns ErrFrozen. Delete and the
+// other deleters, Clear, Resize, Touch and BumpEpoch do not change the items,
+// and GetAndTouchMany does not extend the expirations.
+// Methods which report whether they modified the cache, such as Add, Replace,
+// CompareAndDelete and RemoveOldest, report false.
+//
+// Expired items are not deleted, so the janitor is effectively paused and
+// resumes with the next sweep after Unfreeze. Values of loaders which are in
+// flight, such as of GetOrLoad, are returned to the callers but not stored.
+// Reads proceed normally, and they still update the cache replacement policy
+// and renew sliding expirations.
+func (c *Cache[K, V]) Freeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frozen = true
+}
+
+// Unfreeze makes the cache mutable again after Freeze.
+func (c *Cache[K, V]) Unfreeze() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.frozen = false
+}
+
+// Frozen reports whether the cache is frozen by Freeze.
+func (c *Cache[K, V]) Frozen() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.frozen
+}
@@ -0,0 +1,54 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestFreeze(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(time.Second))
+	c.Freeze()
+	if !c.Frozen() {
+		t.Fatal("want the cache to be frozen")
+	}
+
+	cache.SetNowFunc(now.Add(time.Minute))
+	c.Set("a", 10)
+	if err := c.TrySet("c", 3); !errors.Is(err, cache.ErrFrozen) {
+		t.Fatalf("want ErrFrozen but got %v", err)
+	}
+	if c.Add("d", 4) || c.Replace("a", 10) || c.CompareAndDelete("a", 1) {
+		t.Fatal("want conditional updates to report false")
+	}
+	if _, ok := c.GetAndDelete("a"); ok {
+		t.Fatal("want GetAndDelete to report false")
+	}
+	c.Delete("a")
+	c.DeleteExpired()
+	if got := c.Clear(); got != 0 {
+		t.Fatalf("want Clear to delete nothing but got %d", got)
+	}
+
+	if got, ok := c.Get("a"); !ok || got != 1 {
+		t.Fatalf("want a to be kept but got %v %v", got, ok)
+	}
+	if got := c.Len(); got != 2 {
+		t.Fatalf("want the expired item to be kept but got %d items", got)
+	}
+
+	c.Unfreeze()
+	c.DeleteExpired()
+	c.Set("a", 10)
+	if got, _ := c.Get("a"); got != 10 || c.Len() != 1 {
+		t.Fatalf("want the cache to be mutable again but got %v with %d items", got, c.Len())
+	}
+}
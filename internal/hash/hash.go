@@ -0,0 +1,47 @@
+// Package hash provides the hash function for arbitrary comparable keys,
+// which is shared by the cache and the cache replacement policies.
+package hash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// Key returns a 64-bit FNV-1a hash of the key.
+//
+// Common key types are hashed from their binary representation, and any
+// other comparable types are hashed from their Go-syntax representation.
+func Key[K comparable](key K) uint64 {
+	h := fnv.New64a()
+	var buf [8]byte
+	switch k := any(key).(type) {
+	case string:
+		h.Write([]byte(k))
+	case int:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:])
+	case int64:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:])
+	case int32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:])
+	case uint:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:])
+	case uint64:
+		binary.LittleEndian.PutUint64(buf[:], k)
+		h.Write(buf[:])
+	case uint32:
+		binary.LittleEndian.PutUint64(buf[:], uint64(k))
+		h.Write(buf[:])
+	case float64:
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(k))
+		h.Write(buf[:])
+	default:
+		fmt.Fprintf(h, "%#v", key)
+	}
+	return h.Sum64()
+}
@@ -12,6 +12,10 @@ type janitor struct {
 	interval time.Duration
 	done     chan struct{}
 	once     sync.Once
+	// reset receives a new interval for the running ticker.
+	reset chan time.Duration
+	// exited is closed when the goroutine started by run returns.
+	exited chan struct{}
 }
 
 func newJanitor(ctx context.Context, interval time.Duration) *janitor {
@@ -19,6 +23,8 @@ func newJanitor(ctx context.Context, interval time.Duration) *janitor {
 		ctx:      ctx,
 		interval: interval,
 		done:     make(chan struct{}),
+		reset:    make(chan time.Duration),
+		exited:   make(chan struct{}),
 	}
 	return j
 }
@@ -28,15 +34,32 @@ func (j *janitor) stop() {
 	j.once.Do(func() { close(j.done) })
 }
 
+// setInterval changes the interval of the running janitor. It does nothing
+// after the janitor has finished.
+func (j *janitor) setInterval(d time.Duration) {
+	select {
+	case j.reset <- d:
+	case <-j.exited:
+	}
+}
+
+// wait waits until the goroutine started by run returns.
+func (j *janitor) wait() {
+	<-j.exited
+}
+
 // run with the given cleanup callback function.
 func (j *janitor) run(cleanup func()) {
 	go func() {
+		defer close(j.exited)
 		ticker := time.NewTicker(j.interval)
 		defer ticker.Stop()
 		for {
 			select {
 			case <-ticker.C:
 				cleanup()
+			case d := <-j.reset:
+				ticker.Reset(d)
 			case <-j.done:
 				cleanup() // last call
 				return
@@ -46,3 +69,62 @@ func (j *janitor) run(cleanup func()) {
 		}
 	}()
 }
+
+// JanitorPool is a janitor which is shared by many caches.
+//
+// A single goroutine drives expiration for all of the registered caches
+// on a shared schedule, calling each cache's DeleteExpired in turn.
+type JanitorPool struct {
+	janitor  *janitor
+	mu       sync.Mutex
+	cleanups map[interface{}]func()
+}
+
+// NewJanitorPool creates a new JanitorPool which collects expired items of the
+// registered caches every interval. The pool will be stopped when the context is cancelled.
+func NewJanitorPool(ctx context.Context, interval time.Duration) *JanitorPool {
+	p := &JanitorPool{
+		janitor:  newJanitor(ctx, interval),
+		cleanups: make(map[interface{}]func()),
+	}
+	p.janitor.run(p.cleanup)
+	return p
+}
+
+// Stop stops the pool. The registered caches are not cleaned anymore.
+func (p *JanitorPool) Stop() {
+	p.janitor.stop()
+}
+
+// Len returns the number of caches which are registered with the pool.
+func (p *JanitorPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.cleanups)
+}
+
+func (p *JanitorPool) register(key interface{}, cleanup func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cleanups[key] = cleanup
+}
+
+func (p *JanitorPool) unregister(key interface{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.cleanups, key)
+}
+
+// cleanup calls all of the registered cleanup functions in turn.
+func (p *JanitorPool) cleanup() {
+	p.mu.Lock()
+	cleanups := make([]func(), 0, len(p.cleanups))
+	for _, cleanup := range p.cleanups {
+		cleanups = append(cleanups, cleanup)
+	}
+	p.mu.Unlock()
+
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+}
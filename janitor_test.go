@@ -34,3 +34,116 @@ func TestJanitor(t *testing.T) {
 		t.Fatalf("failed to call clean callback in janitor: %d", got)
 	}
 }
+
+func TestJanitorPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pool := NewJanitorPool(ctx, time.Millisecond)
+	defer pool.Stop()
+
+	cctx, ccancel := context.WithCancel(context.Background())
+	c1 := NewContext(cctx, WithSharedJanitor[string, int](pool))
+	c2 := New(WithSharedJanitor[string, int](pool))
+	if c1.janitor != nil || c2.janitor != nil {
+		t.Fatal("want no dedicated janitor")
+	}
+	if got := pool.Len(); got != 2 {
+		t.Fatalf("want 2 registered caches but got %d", got)
+	}
+
+	c1.Set("a", 1, WithExpiration(-time.Second))
+	c2.Set("b", 2, WithExpiration(-time.Second))
+	time.Sleep(20 * time.Millisecond)
+	if len(c1.Keys()) != 0 || len(c2.Keys()) != 0 {
+		t.Fatal("want expired items to be deleted by the pool")
+	}
+
+	ccancel()
+	deadline := time.After(time.Second)
+	for pool.Len() != 1 {
+		select {
+		case <-deadline:
+			t.Fatalf("want the cache to be unregistered, but got %d", pool.Len())
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	var evicted []string
+	c := New(
+		WithEvictionCallback(func(key string, _ int) { evicted = append(evicted, key) }),
+		WithEvictionOnClose[string, int](),
+	)
+	c.Set("a", 1)
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-c.janitor.exited:
+	default:
+		t.Fatal("want the janitor to be stopped")
+	}
+	if len(evicted) != 1 || evicted[0] != "a" || c.Len() != 0 {
+		t.Fatalf("want the remaining items to be evicted, but got %v", evicted)
+	}
+
+	// double Close does not panic, nor evict again.
+	c.Set("b", 2)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(evicted) != 1 {
+		t.Fatalf("want no eviction by the second Close, but got %v", evicted)
+	}
+}
+
+func TestCloseSharedJanitor(t *testing.T) {
+	pool := NewJanitorPool(context.Background(), time.Minute)
+	defer pool.Stop()
+
+	c := New(WithSharedJanitor[string, int](pool))
+	c.Set("a", 1)
+	c.Close()
+	if got := pool.Len(); got != 0 {
+		t.Fatalf("want the cache to be unregistered, but got %d", got)
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("want the items to be kept w/o WithEvictionOnClose")
+	}
+}
+
+func TestSetJanitorInterval(t *testing.T) {
+	c := New(WithJanitorInterval[string, int](time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, WithExpiration(time.Millisecond))
+	c.SetJanitorInterval(time.Millisecond)
+
+	deadline := time.After(time.Second)
+	for c.Len() != 0 {
+		select {
+		case <-deadline:
+			t.Fatal("want the expired item to be deleted by the janitor")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	c.Close()
+	// does not block after the janitor has been stopped.
+	c.SetJanitorInterval(time.Second)
+}
+
+func TestPurgeExpired(t *testing.T) {
+	c := New(WithJanitorInterval[string, int](time.Hour))
+	defer c.Close()
+
+	c.Set("a", 1, WithExpiration(-time.Second))
+	c.Set("b", 2)
+	c.PurgeExpired()
+	if got := c.Keys(); len(got) != 1 || got[0] != "b" {
+		t.Fatalf("want only the live item, but got %v", got)
+	}
+}
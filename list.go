@@ -0,0 +1,54 @@
+package cache
+
+// PushBack appends val to the slice stored with key, like RPUSH of Redis, and
+// returns the new length. The slice is created on the first call for the key,
+// and opts are applied to it. Otherwise the expiration of the slice is kept
+// unless opts specify another. This is done under the write lock of the cache.
+//
+// Slices returned by Get are not changed by PushBack and PopFront, though they
+// may share the underlying array. They must not be modified by callers.
+func PushBack[K comparable, E any](c *Cache[K, []E], key K, val E, opts ...ItemOption) int {
+	c.mu.Lock()
+	defer c.unlock()
+	item, s, ok := c.peekItem(key)
+	if ok {
+		opts = append([]ItemOption{keepExpiration(item)}, opts...)
+	}
+	// appends beyond the length of any slice which has been returned, since
+	// the stored slice only shrinks from the front.
+	s = append(s, val)
+	if c.store(key, s, opts...) != nil {
+		return len(s) - 1
+	}
+	return len(s)
+}
+
+// PopFront removes the first element of the slice stored with key and returns
+// it, like LPOP of Redis. The slice is deleted from the cache when the last
+// element is removed. ok is false if the key is not found or the slice has
+// been expired. The expiration of the slice is kept. This is done under the
+// write lock of the cache.
+func PopFront[K comparable, E any](c *Cache[K, []E], key K) (val E, ok bool) {
+	c.mu.Lock()
+	defer c.unlock()
+	item, s, ok := c.peekItem(key)
+	if !ok || len(s) == 0 || c.frozen {
+		return val, false
+	}
+	if len(s) == 1 {
+		c.delete(key)
+		return s[0], true
+	}
+	c.set(key, s[1:], keepExpiration(item))
+	return s[0], true
+}
+
+// LLen returns the length of the slice stored with key, like LLEN of Redis.
+// It returns 0 if the key is not found or the slice has been expired. The
+// eviction order of the cache replacement policy is not affected.
+func LLen[K comparable, E any](c *Cache[K, []E], key K) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, s, _ := c.peekItem(key)
+	return len(s)
+}
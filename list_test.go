@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestList(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, []int]()
+	if got := cache.PushBack(c, "q", 1, cache.WithExpiration(time.Minute)); got != 1 {
+		t.Fatalf("want length 1 but got %d", got)
+	}
+	cache.PushBack(c, "q", 2)
+	s, _ := c.Get("q")
+	cache.PushBack(c, "q", 3)
+	if got := cache.LLen(c, "q"); got != 3 {
+		t.Fatalf("want length 3 but got %d", got)
+	}
+
+	for _, want := range []int{1, 2} {
+		if got, ok := cache.PopFront(c, "q"); !ok || got != want {
+			t.Fatalf("want %d but got %v %v", want, got, ok)
+		}
+	}
+	if len(s) != 2 || s[0] != 1 || s[1] != 2 {
+		t.Fatalf("want the returned slice not to be changed but got %v", s)
+	}
+	if _, exp, _ := c.GetWithExpiration("q"); !exp.Equal(now.Add(time.Minute)) {
+		t.Fatalf("want the expiration to be kept but got %v", exp)
+	}
+	if got, ok := cache.PopFront(c, "q"); !ok || got != 3 || c.Contains("q") {
+		t.Fatalf("want q to be deleted with the last element but got %v %v", got, ok)
+	}
+	if _, ok := cache.PopFront(c, "q"); ok {
+		t.Fatal("want false for the missing key")
+	}
+
+	cache.PushBack(c, "expired", 1, cache.WithExpiration(time.Second))
+	cache.SetNowFunc(now.Add(time.Minute))
+	if _, ok := cache.PopFront(c, "expired"); ok || cache.LLen(c, "expired") != 0 {
+		t.Fatal("want the expired slice not to be found")
+	}
+}
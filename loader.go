@@ -0,0 +1,361 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrAbsent is returned by GetOrLoad when the key has been marked by MarkAbsent.
+var ErrAbsent = errors.New("cache: key is known to be absent")
+
+// ErrNotFound is returned by a loader of GetOrLoad to report that the key does
+// not exist in the backend. The absence is cached with WithNegativeTTL.
+var ErrNotFound = errors.New("cache: key is not found")
+
+// ErrLoaderPanic is returned by GetOrLoad and the other loading methods when
+// the loader panics and the panic cannot be propagated to the caller, such as
+// to the callers waiting for the call of another caller. The returned error
+// wraps ErrLoaderPanic and has the panic value in its message.
+var ErrLoaderPanic = errors.New("cache: loader panicked")
+
+// WithNegativeTTL is an option to cache the absence of keys for d when the
+// loader of GetOrLoad returns ErrNotFound (or an error wrapping it), so the
+// loader is not called again for the key until d has passed. Meanwhile
+// GetOrLoad returns ErrNotFound, and Get returns ok=false as usual.
+//
+// The absence is kept apart from the items, so it takes no room of the cache.
+// The expiration of items which are set by GetOrLoad or Set is not affected
+// by d, and a value stored for the key is returned even within d.
+func WithNegativeTTL[K comparable, V any](d time.Duration) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.negativeTTL = d
+	}
+}
+
+// call is an in-flight or completed loader call.
+type call[V any] struct {
+	done chan struct{} // closed when the call is completed
+	val  V
+	err  error
+}
+
+// setPanic records the recovered panic value r of the loader as the error of
+// the call if it is not nil, so that the waiters do not receive the zero value
+// w/o error. It returns r.
+func (c *call[V]) setPanic(r interface{}) interface{} {
+	if r != nil {
+		c.err = fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+	}
+	return r
+}
+
+// group deduplicates concurrent loader calls for the same key.
+// The zero value is ready to use.
+type group[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*call[V]
+}
+
+// do calls fn once for the key at a time. Concurrent callers for the same key
+// wait for the in-flight call and receive the same result.
+func (g *group[K, V]) do(key K, fn func() (V, error)) (V, error) {
+	c, leader := g.start(key)
+	if !leader {
+		<-c.done
+		return c.val, c.err
+	}
+	defer g.finish(key, c)
+	defer func() {
+		// the panic is propagated to the caller, and the waiters get an error.
+		if r := c.setPanic(recover()); r != nil {
+			panic(r)
+		}
+	}()
+	c.val, c.err = fn()
+	return c.val, c.err
+}
+
+// doContext calls fn like do, but returns ctx.Err() as soon as the context is
+// done, even if fn is still running. fn is run in another goroutine so that
+// the caller can stop waiting for it.
+func (g *group[K, V]) doContext(ctx context.Context, key K, fn func() (V, error)) (V, error) {
+	if ctx.Done() == nil {
+		// the context is never done.
+		return g.do(key, fn)
+	}
+	c, leader := g.start(key)
+	if leader {
+		go func() {
+			defer g.finish(key, c)
+			// a panic in this goroutine cannot be recovered by any caller.
+			defer func() { c.setPanic(recover()) }()
+			c.val, c.err = fn()
+		}()
+	}
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// start returns the in-flight call for the key, or starts a new call if there
+// is none. leader reports whether the call has been started by the caller.
+func (g *group[K, V]) start(key K) (c *call[V], leader bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls == nil {
+		g.calls = make(map[K]*call[V])
+	}
+	if c, ok := g.calls[key]; ok {
+		return c, false
+	}
+	c = &call[V]{done: make(chan struct{})}
+	g.calls[key] = c
+	return c, true
+}
+
+// finish completes the call for the key, and wakes up the waiters.
+func (g *group[K, V]) finish(key K, c *call[V]) {
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(c.done)
+}
+
+// GetOrLoad looks up a key's value from the cache. If it is not found, the
+// value is loaded by the loader and stored with the given options.
+//
+// Concurrent misses for the same key are collapsed into a single loader call,
+// and all of the callers receive the same result. Loads of different keys run
+// in parallel. Errors from the loader are returned as is and are not cached.
+// If BumpEpoch is called while the loader runs, the loaded value is returned
+// but not stored.
+//
+// If the cache is created with WithNegativeBloom and the key may have been
+// marked by MarkAbsent, the loader is not called and ErrAbsent is returned.
+// If the cache is created with WithNegativeTTL and the loader has returned
+// ErrNotFound for the key recently, the loader is not called and ErrNotFound
+// is returned.
+func (c *Cache[K, V]) GetOrLoad(key K, loader func(K) (V, error), opts ...ItemOption) (V, error) {
+	return c.GetOrLoadContext(context.Background(), key, func(_ context.Context, key K) (V, error) {
+		return loader(key)
+	}, opts...)
+}
+
+// GetOrLoadContext looks up a key's value like GetOrLoad, but passes ctx to
+// the loader, and returns ctx.Err() as soon as ctx is done before the load
+// completes.
+//
+// Concurrent misses for the same key are collapsed into a single loader call
+// with ctx of the caller which started it. Each of the waiters stops waiting
+// when its own ctx is done. If the loader fails because ctx of the starting
+// caller is done, the other waiters receive the error of the loader as well.
+func (c *Cache[K, V]) GetOrLoadContext(ctx context.Context, key K, loader func(context.Context, K) (V, error), opts ...ItemOption) (V, error) {
+	if val, ok := c.Get(key); ok {
+		return val, nil
+	}
+	var zero V
+	if c.MaybeAbsent(key) {
+		return zero, ErrAbsent
+	}
+	if c.notFound(key) {
+		return zero, ErrNotFound
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	return c.loads.doContext(ctx, key, func() (V, error) {
+		epoch := c.Epoch()
+		// the value may have been stored while waiting for the previous call.
+		if val, ok := c.peek(key); ok {
+			return val, nil
+		}
+		if c.notFound(key) {
+			return zero, ErrNotFound
+		}
+		val, err := loader(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			c.markNotFound(key)
+		}
+		if err != nil {
+			return val, err
+		}
+		c.Set(key, val, withEpoch(opts, epoch)...)
+		return val, nil
+	})
+}
+
+// GetOrSetFunc returns the existing value for the key if present and not
+// expired like GetOrSet. Otherwise, it computes the value by fn, and stores
+// and returns it with the options returned by fn. The loaded result is true
+// if the value was not computed by this call.
+//
+// fn is called only on a miss, and concurrent misses for the same key are
+// collapsed into a single call like GetOrLoad, but fn cannot fail.
+func (c *Cache[K, V]) GetOrSetFunc(key K, fn func() (V, []ItemOption)) (actual V, loaded bool) {
+	if val, ok := c.Get(key); ok {
+		return val, true
+	}
+	computed := false
+	val, _ := c.loads.do(key, func() (V, error) {
+		epoch := c.Epoch()
+		// the value may have been stored while waiting for the previous call.
+		if val, ok := c.peek(key); ok {
+			return val, nil
+		}
+		val, opts := fn()
+		computed = true
+		c.Set(key, val, withEpoch(opts, epoch)...)
+		return val, nil
+	})
+	return val, !computed
+}
+
+// GetOrRefresh looks up a key's value like GetOrLoad, and also reloads the
+// value in the background if the item expires within refreshAt, so that hot
+// keys are refreshed before they expire and reads do not block on a miss.
+//
+// The current value is returned immediately while it is being refreshed. Only
+// one refresh runs for a key at a time. The refreshed value is stored with the
+// given options, and if the refresh fails, the current value is kept until it
+// expires. Items w/o expiration are never refreshed.
+func (c *Cache[K, V]) GetOrRefresh(key K, loader func(K) (V, error), refreshAt time.Duration, opts ...ItemOption) (V, error) {
+	val, exp, ok := c.GetWithExpiration(key)
+	if !ok {
+		return c.GetOrLoad(key, loader, opts...)
+	}
+	if !exp.IsZero() && exp.Sub(nowFunc()) <= refreshAt && c.refreshes.start(key) {
+		go func() {
+			defer c.refreshes.done(key)
+			epoch := c.Epoch()
+			if val, err := loader(key); err == nil {
+				c.Set(key, val, withEpoch(opts, epoch)...)
+			}
+		}()
+	}
+	return val, nil
+}
+
+// withEpoch returns opts followed by WithEpoch(epoch), so that a value loaded
+// across BumpEpoch is not stored. opts is not modified.
+func withEpoch(opts []ItemOption, epoch uint64) []ItemOption {
+	return append(opts[:len(opts):len(opts)], WithEpoch(epoch))
+}
+
+// keySet is a set of keys which is safe for concurrent use.
+// The zero value is ready to use.
+type keySet[K comparable] struct {
+	mu   sync.Mutex
+	keys map[K]struct{}
+}
+
+// start adds the key, and reports whether it has not been in the set.
+func (s *keySet[K]) start(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[key]; ok {
+		return false
+	}
+	if s.keys == nil {
+		s.keys = make(map[K]struct{})
+	}
+	s.keys[key] = struct{}{}
+	return true
+}
+
+// done removes the key.
+func (s *keySet[K]) done(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, key)
+}
+
+// peek looks up a key's value like Get without collecting statistics or
+// affecting the cache replacement policy.
+func (c *Cache[K, V]) peek(key K) (value V, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, value, ok = c.peekItem(key)
+	return
+}
+
+// notFound reports whether the absence of the key is cached by WithNegativeTTL.
+func (c *Cache[K, V]) notFound(key K) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	exp, ok := c.negatives[key]
+	return ok && nowFunc().Before(exp)
+}
+
+// markNotFound caches the absence of the key for the negative TTL.
+func (c *Cache[K, V]) markNotFound(key K) {
+	if c.negativeTTL <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.negatives == nil {
+		c.negatives = make(map[K]time.Time)
+	}
+	c.negatives[key] = nowFunc().Add(c.negativeTTL)
+}
+
+// deleteNotFound forgets the expired absences of keys.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) deleteNotFound() {
+	if c.negativeTTL <= 0 || len(c.negatives) == 0 {
+		// no need to read the clock, which may be replaced by tests.
+		return
+	}
+	now := nowFunc()
+	for key, exp := range c.negatives {
+		if !now.Before(exp) {
+			delete(c.negatives, key)
+		}
+	}
+}
+
+// keyMutex is a set of mutexes for each key. The mutex of a key is created
+// on demand, and is discarded when it is not held nor waited.
+// The zero value is ready to use.
+type keyMutex[K comparable] struct {
+	mu    sync.Mutex
+	locks map[K]*keyLock
+}
+
+type keyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the mutex of the key, and returns the function to unlock it.
+func (m *keyMutex[K]) lock(key K) (unlock func()) {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[K]*keyLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = new(keyLock)
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		m.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
@@ -0,0 +1,344 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestGetOrLoad(t *testing.T) {
+	c := cache.New[string, int]()
+
+	var calls int64
+	release := make(chan struct{})
+	loader := func(key string) (int, error) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+		return len(key), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := c.GetOrLoad("abc", loader)
+			if err != nil || got != 3 {
+				t.Errorf("want 3 nil but got %d %v", got, err)
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("want the loader to be called once but got %d", got)
+	}
+	if got, ok := c.Get("abc"); !ok || got != 3 {
+		t.Fatalf("want the loaded value to be stored but got %d %v", got, ok)
+	}
+}
+
+func TestGetOrSetFunc(t *testing.T) {
+	c := cache.New[string, int]()
+
+	var calls, computed int64
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	fn := func() (int, []cache.ItemOption) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			close(entered)
+		}
+		<-release
+		return 42, []cache.ItemOption{cache.WithExpiration(time.Hour)}
+	}
+
+	// the result does not depend on whether the other callers arrive before
+	// or after fn returns, since the value is stored before the call ends.
+	var ready, wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		ready.Add(1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ready.Done()
+			got, loaded := c.GetOrSetFunc("answer", fn)
+			if got != 42 {
+				t.Errorf("want 42 but got %d", got)
+			}
+			if !loaded {
+				atomic.AddInt64(&computed, 1)
+			}
+		}()
+	}
+	ready.Wait()
+	<-entered
+	close(release)
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("want fn to be called once but got %d", got)
+	}
+	if got := atomic.LoadInt64(&computed); got != 1 {
+		t.Fatalf("want a single caller to compute the value but got %d", got)
+	}
+	if _, exp, ok := c.GetWithExpiration("answer"); !ok || exp.IsZero() {
+		t.Fatalf("want the value to be stored with the options of fn but got %v %v", exp, ok)
+	}
+	if got, loaded := c.GetOrSetFunc("answer", fn); got != 42 || !loaded {
+		t.Fatalf("want 42 true but got %d %v", got, loaded)
+	}
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("want fn not to be called on a hit but got %d calls", got)
+	}
+}
+
+func TestGetOrLoadError(t *testing.T) {
+	c := cache.New[string, int]()
+	errLoad := errors.New("load")
+
+	_, err := c.GetOrLoad("a", func(string) (int, error) { return 0, errLoad })
+	if err != errLoad {
+		t.Fatalf("want %v but got %v", errLoad, err)
+	}
+	if c.Contains("a") {
+		t.Fatal("want errors not to be cached")
+	}
+
+	got, err := c.GetOrLoad("a", func(string) (int, error) { return 1, nil })
+	if err != nil || got != 1 {
+		t.Fatalf("want 1 nil but got %d %v", got, err)
+	}
+}
+
+func TestGetOrLoadAbsent(t *testing.T) {
+	c := cache.New(cache.WithNegativeBloom[string, int](100, 0.01))
+	c.MarkAbsent("a")
+
+	called := false
+	_, err := c.GetOrLoad("a", func(string) (int, error) {
+		called = true
+		return 1, nil
+	})
+	if err != cache.ErrAbsent || called {
+		t.Fatalf("want ErrAbsent without calling the loader but got %v %v", err, called)
+	}
+}
+
+func TestGetOrLoadNegativeTTL(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New(cache.WithNegativeTTL[string, int](time.Minute))
+	calls := 0
+	found := false
+	loader := func(key string) (int, error) {
+		calls++
+		if !found {
+			return 0, fmt.Errorf("%s: %w", key, cache.ErrNotFound)
+		}
+		return 1, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetOrLoad("a", loader); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("want ErrNotFound but got %v", err)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("want the absence to be cached, but the loader was called %d times", calls)
+	}
+	if _, ok := c.Get("a"); ok {
+		t.Fatal("want Get to report the absent key as not found")
+	}
+
+	found = true
+	cache.SetNowFunc(now.Add(2 * time.Minute))
+	if got, err := c.GetOrLoad("a", loader); err != nil || got != 1 {
+		t.Fatalf("want 1 after the negative TTL, but got %v %v", got, err)
+	}
+	if calls != 2 {
+		t.Fatalf("want the loader to be called again, but got %d calls", calls)
+	}
+}
+
+func TestGetOrRefresh(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	var calls int64
+	refreshed := make(chan struct{}, 1)
+	release := make(chan struct{})
+	fail := false
+	loader := func(key string) (int, error) {
+		n := atomic.AddInt64(&calls, 1)
+		if n == 1 {
+			return 1, nil
+		}
+		<-release
+		defer func() { refreshed <- struct{}{} }()
+		if fail {
+			return 0, errors.New("failed")
+		}
+		return int(n), nil
+	}
+
+	if got, err := c.GetOrRefresh("a", loader, 10*time.Second, cache.WithExpiration(time.Minute)); err != nil || got != 1 {
+		t.Fatalf("want 1 loaded synchronously, but got %v %v", got, err)
+	}
+	if got, _ := c.GetOrRefresh("a", loader, 10*time.Second, cache.WithExpiration(time.Minute)); got != 1 || atomic.LoadInt64(&calls) != 1 {
+		t.Fatal("want no refresh far from the expiration")
+	}
+
+	// within refreshAt of the expiration, the stale value is returned at once.
+	cache.SetNowFunc(now.Add(55 * time.Second))
+	for i := 0; i < 3; i++ {
+		if got, err := c.GetOrRefresh("a", loader, 10*time.Second, cache.WithExpiration(time.Minute)); err != nil || got != 1 {
+			t.Fatalf("want the current value 1, but got %v %v", got, err)
+		}
+	}
+	close(release)
+	<-refreshed
+	deadline := time.After(time.Second)
+	for {
+		if got, _ := c.Get("a"); got == 2 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("want the value to be refreshed")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	if got := atomic.LoadInt64(&calls); got != 2 {
+		t.Fatalf("want a single refresh, but got %d loader calls", got)
+	}
+
+	// a failed refresh keeps the current value until it expires.
+	fail = true
+	cache.SetNowFunc(now.Add(110 * time.Second))
+	if got, _ := c.GetOrRefresh("a", loader, 10*time.Second); got != 2 {
+		t.Fatalf("want the current value 2, but got %v", got)
+	}
+	<-refreshed
+	if got, ok := c.Get("a"); !ok || got != 2 {
+		t.Fatalf("want the value to be kept, but got %v %v", got, ok)
+	}
+}
+
+func TestGetOrLoadContext(t *testing.T) {
+	c := cache.New[string, int]()
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, error) {
+		close(started)
+		select {
+		case <-release:
+			return 1, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+
+	// the waiter gives up when its own context is done.
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := c.GetOrLoadContext(context.Background(), "a", loader)
+		leaderDone <- err
+	}()
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetOrLoadContext(ctx, "a", loader); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want the deadline to be exceeded, but got %v", err)
+	}
+
+	close(release)
+	if err := <-leaderDone; err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := c.Get("a"); !ok || got != 1 {
+		t.Fatalf("want the loaded value, but got %v %v", got, ok)
+	}
+
+	// nothing is loaded with the context which is already done.
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.GetOrLoadContext(ctx, "b", loader); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want the context to be canceled, but got %v", err)
+	}
+	if c.Contains("b") {
+		t.Fatal("want nothing to be stored")
+	}
+}
+
+func TestGetOrLoadPanic(t *testing.T) {
+	c := cache.New[string, int]()
+	loader := func(context.Context, string) (int, error) {
+		panic("boom")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := c.GetOrLoadContext(ctx, "a", loader)
+	if !errors.Is(err, cache.ErrLoaderPanic) || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("want the panic as an error but got %v", err)
+	}
+	if c.Contains("a") {
+		t.Fatal("want nothing to be stored")
+	}
+
+	// the panic of the synchronous load is propagated to the caller.
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("want the panic to be propagated but got %v", r)
+			}
+		}()
+		c.GetOrLoad("a", func(string) (int, error) { panic("boom") })
+	}()
+}
+
+func TestGetOrLoadBumpEpoch(t *testing.T) {
+	c := cache.New[string, int]()
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.GetOrLoad("a", func(string) (int, error) {
+			close(entered)
+			<-release
+			return 1, nil
+		})
+	}()
+
+	<-entered
+	c.BumpEpoch()
+	close(release)
+	<-done
+
+	if got, ok := c.Get("a"); ok {
+		t.Fatalf("want the value loaded before the bump not to be stored but got %d", got)
+	}
+	got, err := c.GetOrLoad("a", func(string) (int, error) { return 2, nil })
+	if err != nil || got != 2 {
+		t.Fatalf("want 2 <nil> but got %d %v", got, err)
+	}
+	if got, ok := c.Get("a"); !ok || got != 2 {
+		t.Fatalf("want 2 true but got %d %v", got, ok)
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import "strings"
+
+// Namespaced is a view of a string keyed Cache whose keys are prefixed.
+//
+// Several namespaces can share a single underlying Cache, and so its
+// capacity and janitor, without colliding on keys.
+type Namespaced[V any] struct {
+	cache  *Cache[string, V]
+	prefix string
+}
+
+// Namespace returns a view of c which transparently prepends prefix to every key.
+func Namespace[V any](c *Cache[string, V], prefix string) *Namespaced[V] {
+	return &Namespaced[V]{
+		cache:  c,
+		prefix: prefix,
+	}
+}
+
+// Get looks up a key's value from the namespace.
+func (n *Namespaced[V]) Get(key string) (value V, ok bool) {
+	return n.cache.Get(n.prefix + key)
+}
+
+// Set sets a value to the namespace with key. replacing any existing value.
+func (n *Namespaced[V]) Set(key string, val V, opts ...ItemOption) {
+	n.cache.Set(n.prefix+key, val, opts...)
+}
+
+// Delete deletes the item with provided key from the namespace.
+func (n *Namespaced[V]) Delete(key string) {
+	n.cache.Delete(n.prefix + key)
+}
+
+// Contains reports whether key is within the namespace.
+func (n *Namespaced[V]) Contains(key string) bool {
+	return n.cache.Contains(n.prefix + key)
+}
+
+// Keys returns the keys of the namespace without the prefix.
+// the order is relied on algorithms.
+func (n *Namespaced[V]) Keys() []string {
+	keys := n.cache.Keys()
+	ret := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if strings.HasPrefix(key, n.prefix) {
+			ret = append(ret, key[len(n.prefix):])
+		}
+	}
+	return ret
+}
+
+// List returns the items of the namespace keyed without the prefix.
+func (n *Namespaced[V]) List() map[string]V {
+	items := make(map[string]V)
+	for key, val := range n.cache.List() {
+		if strings.HasPrefix(key, n.prefix) {
+			items[key[len(n.prefix):]] = val
+		}
+	}
+	return items
+}
+
+// Flush deletes all items of the namespace. Items of other namespaces are kept.
+func (n *Namespaced[V]) Flush() {
+	DeletePrefix(n.cache, n.prefix)
+}
+
+// DeletePrefix deletes all items of c whose keys start with prefix at once,
+// and returns how many items are deleted. The eviction callback is called for
+// each of them as Delete does.
+func DeletePrefix[V any](c *Cache[string, V], prefix string) int {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.frozen {
+		return 0
+	}
+	n := 0
+	for _, key := range c.cache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			c.delete(key)
+			n++
+		}
+	}
+	return n
+}
+
+// KeysWithPrefix returns the keys of c which start with prefix.
+// the order is relied on algorithms.
+func KeysWithPrefix[V any](c *Cache[string, V], prefix string) []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var keys []string
+	for _, key := range c.cache.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
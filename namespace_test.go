@@ -0,0 +1,82 @@
+package cache_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestNamespace(t *testing.T) {
+	c := cache.New[string, int]()
+	users := cache.Namespace(c, "users:")
+	posts := cache.Namespace(c, "posts:")
+
+	users.Set("1", 10)
+	users.Set("2", 20)
+	posts.Set("1", 100)
+
+	if got, ok := users.Get("1"); !ok || got != 10 {
+		t.Fatalf("want 10 true but got %d %v", got, ok)
+	}
+	if got, ok := posts.Get("1"); !ok || got != 100 {
+		t.Fatalf("want 100 true but got %d %v", got, ok)
+	}
+	if !c.Contains("users:2") || !users.Contains("2") || posts.Contains("2") {
+		t.Fatal("invalid Contains")
+	}
+
+	keys := users.Keys()
+	sort.Strings(keys)
+	if got := strings.Join(keys, ","); got != "1,2" {
+		t.Fatalf("want %q but got %q", "1,2", got)
+	}
+	if got := posts.List(); len(got) != 1 || got["1"] != 100 {
+		t.Fatalf("want map[1:100] but got %v", got)
+	}
+
+	users.Delete("2")
+	if users.Contains("2") {
+		t.Fatal("want deleted")
+	}
+
+	users.Flush()
+	if len(users.Keys()) != 0 {
+		t.Fatalf("want empty namespace but got %v", users.Keys())
+	}
+	if _, ok := posts.Get("1"); !ok {
+		t.Fatal("want other namespaces to be kept by Flush")
+	}
+}
+
+func TestDeletePrefix(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.WithEvictionCallback(func(key string, _ int) {
+			evicted = append(evicted, key)
+		}),
+	)
+	c.Set("user:42:profile", 1)
+	c.Set("user:42:posts", 2)
+	c.Set("user:7:profile", 3)
+
+	keys := cache.KeysWithPrefix(c, "user:42:")
+	sort.Strings(keys)
+	if got := strings.Join(keys, ","); got != "user:42:posts,user:42:profile" {
+		t.Fatalf("want the keys of user 42 but got %q", got)
+	}
+
+	if got := cache.DeletePrefix(c, "user:42:"); got != 2 {
+		t.Fatalf("want 2 deleted items but got %d", got)
+	}
+	if got := c.Keys(); len(got) != 1 || got[0] != "user:7:profile" {
+		t.Fatalf("want only the item of user 7 but got %v", got)
+	}
+	if len(evicted) != 2 {
+		t.Fatalf("want the eviction callback for 2 items but got %v", evicted)
+	}
+	if got := cache.DeletePrefix(c, "user:42:"); got != 0 {
+		t.Fatalf("want no deleted items but got %d", got)
+	}
+}
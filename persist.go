@@ -0,0 +1,204 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"time"
+
+	"github.com/gekatateam/go-generics-cache/policy/mru"
+)
+
+// persistedItem is the serialized form of an item.
+type persistedItem[K comparable, V any] struct {
+	Key        K
+	Value      V
+	Expiration time.Time
+}
+
+// persistedItems returns the live items in the order which reproduces the
+// eviction order of the cache replacement policy when they are set again.
+// The caller must hold the lock.
+func (c *Cache[K, V]) persistedItems() []persistedItem[K, V] {
+	keys := c.cache.Keys()
+	if _, ok := c.cache.(*mru.Cache[K, *Item[K, V]]); ok {
+		// the mru cache lists keys from the most recently used one.
+		for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+			keys[i], keys[j] = keys[j], keys[i]
+		}
+	}
+	items := make([]persistedItem[K, V], 0, len(keys))
+	for _, key := range keys {
+		item, val, ok := c.peekItem(key)
+		if !ok {
+			continue
+		}
+		items = append(items, persistedItem[K, V]{
+			Key:        key,
+			Value:      val,
+			Expiration: item.Expiration,
+		})
+	}
+	return items
+}
+
+// restore sets the items which have not been expired in order.
+func (c *Cache[K, V]) restore(items []persistedItem[K, V]) {
+	c.mu.Lock()
+	defer c.unlock()
+	for _, item := range items {
+		c.storeUntil(item.Key, item.Value, item.Expiration)
+	}
+}
+
+// SetItems sets the Key, Value and Expiration of each item under a single
+// lock, so items with their own expirations can be loaded at once, such as
+// the items of Snapshot. The zero Expiration means w/o expiration even if the
+// cache is created with WithDefaultExpiration, and items which have already
+// been expired are skipped. They are set in order according to the overflow
+// policy, and ones which are rejected are skipped.
+func (c *Cache[K, V]) SetItems(items []Item[K, V]) {
+	if c.trace != nil {
+		for i := range items {
+			c.trace.record(traceSet, items[i].Key)
+		}
+	}
+	c.mu.Lock()
+	defer c.unlock()
+	for i := range items {
+		c.storeUntil(items[i].Key, items[i].Value, items[i].Expiration)
+	}
+}
+
+// storeUntil stores a value which expires at exp unless it has already been
+// expired. The caller must hold the write lock.
+func (c *Cache[K, V]) storeUntil(key K, val V, exp time.Time) {
+	if !exp.IsZero() && !nowFunc().Before(exp) {
+		return
+	}
+	_ = c.store(key, val, withExpirationTime(exp))
+}
+
+// Snapshot returns a point-in-time copy of the items which have not been
+// expired, including their expirations. It is taken under a single read lock,
+// and the returned map can be scanned w/o the lock of the cache.
+func (c *Cache[K, V]) Snapshot() map[K]Item[K, V] {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	items := c.persistedItems()
+	snapshot := make(map[K]Item[K, V], len(items))
+	for _, item := range items {
+		snapshot[item.Key] = Item[K, V]{
+			Key:        item.Key,
+			Value:      item.Value,
+			Expiration: item.Expiration,
+		}
+	}
+	return snapshot
+}
+
+// Clone creates a new independent cache by New with opts, and copies the items
+// which have not been expired to it in the eviction order of the cache. The
+// cache replacement policy is not copied, so opts should specify the same one.
+func (c *Cache[K, V]) Clone(opts ...Option[K, V]) *Cache[K, V] {
+	c.mu.RLock()
+	items := c.persistedItems()
+	c.mu.RUnlock()
+	clone := New(opts...)
+	clone.restore(items)
+	return clone
+}
+
+// withExpirationTime is an option to set the absolute expiration time.
+// The zero time means w/o expiration.
+func withExpirationTime(t time.Time) ItemOption {
+	return func(o *itemOptions) {
+		o.expiration = t
+		o.ttl, o.hasTTL = 0, false
+		o.noExpiration = t.IsZero()
+		o.sliding = 0
+		o.jitter = 0
+	}
+}
+
+// GobEncode encodes the keys, values and expirations of the items which have
+// not been expired, so that the cache can be persisted and restored by GobDecode.
+//
+// K and V must be encodable by encoding/gob. Interface values must be registered
+// by gob.Register.
+func (c *Cache[K, V]) GobEncode() ([]byte, error) {
+	c.mu.RLock()
+	items := c.persistedItems()
+	c.mu.RUnlock()
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(items); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode decodes the items encoded by GobEncode and sets them to the cache,
+// replacing any existing values of the same keys. Items which have been expired
+// in the meantime are skipped.
+//
+// The cache must be created by New or its variants before decoding, so that the
+// options such as the cache replacement policy are applied.
+func (c *Cache[K, V]) GobDecode(data []byte) error {
+	var items []persistedItem[K, V]
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&items); err != nil {
+		return err
+	}
+	c.restore(items)
+	return nil
+}
+
+// jsonItem is the JSON form of an item.
+type jsonItem[K comparable, V any] struct {
+	Key        K          `json:"key"`
+	Value      V          `json:"value"`
+	Expiration *time.Time `json:"expiration,omitempty"`
+}
+
+// MarshalJSON encodes the items which have not been expired as an array of
+// {"key", "value", "expiration"} objects. The expiration is omitted for items
+// w/o expiration. The items are ordered as the eviction order is reproduced
+// by UnmarshalJSON.
+//
+// K and V must be encodable by encoding/json.
+func (c *Cache[K, V]) MarshalJSON() ([]byte, error) {
+	c.mu.RLock()
+	items := c.persistedItems()
+	c.mu.RUnlock()
+
+	out := make([]jsonItem[K, V], len(items))
+	for i, item := range items {
+		out[i] = jsonItem[K, V]{Key: item.Key, Value: item.Value}
+		if !item.Expiration.IsZero() {
+			exp := item.Expiration
+			out[i].Expiration = &exp
+		}
+	}
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON decodes the items encoded by MarshalJSON and sets them to the
+// cache in order, replacing any existing values of the same keys. Items which
+// have been expired are skipped.
+//
+// The cache must be created by New or its variants before decoding like GobDecode.
+func (c *Cache[K, V]) UnmarshalJSON(data []byte) error {
+	var in []jsonItem[K, V]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	items := make([]persistedItem[K, V], len(in))
+	for i, item := range in {
+		items[i] = persistedItem[K, V]{Key: item.Key, Value: item.Value}
+		if item.Expiration != nil {
+			items[i].Expiration = *item.Expiration
+		}
+	}
+	c.restore(items)
+	return nil
+}
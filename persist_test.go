@@ -0,0 +1,168 @@
+package cache_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+)
+
+func TestGob(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(4)))
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(time.Minute))
+	c.Set("c", 3, cache.WithExpiration(time.Hour))
+	c.Set("d", 4, cache.WithExpiration(-time.Second))
+	c.Get("a")
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(c); err != nil {
+		t.Fatal(err)
+	}
+
+	cache.SetNowFunc(now.Add(2 * time.Minute))
+	restored := cache.New(cache.AsLRU[string, int](lru.WithCapacity(4)))
+	if err := gob.NewDecoder(&buf).Decode(restored); err != nil {
+		t.Fatal(err)
+	}
+
+	// b has been expired before decoding.
+	if got, want := strings.Join(restored.Keys(), ","), "c,a"; got != want {
+		t.Fatalf("want keys %q but got %q", want, got)
+	}
+	if got, ok := restored.Get("a"); !ok || got != 1 {
+		t.Fatalf("want 1 true but got %d %v", got, ok)
+	}
+	if d, ok := restored.TTL("a"); !ok || d != cache.NoExpiration {
+		t.Fatalf("want a w/o expiration but got %v %v", d, ok)
+	}
+	if d, ok := restored.TTL("c"); !ok || d != time.Hour-2*time.Minute {
+		t.Fatalf("want the expiration of c to be kept but got %v %v", d, ok)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New(cache.AsFIFO[string, int]())
+	c.Set("b", 2, cache.WithExpiration(time.Minute))
+	c.Set("a", 1)
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"key":"b","value":2,"expiration":"2022-01-01T00:01:00Z"},{"key":"a","value":1}]`
+	if string(data) != want {
+		t.Fatalf("want %s but got %s", want, data)
+	}
+
+	fixture := `[
+		{"key": "x", "value": 10},
+		{"key": "y", "value": 20, "expiration": "2021-12-31T23:59:00Z"},
+		{"key": "z", "value": 30, "expiration": "2022-01-01T01:00:00Z"}
+	]`
+	restored := cache.New(cache.AsFIFO[string, int]())
+	if err := json.Unmarshal([]byte(fixture), restored); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := strings.Join(restored.Keys(), ","), "x,z"; got != want {
+		t.Fatalf("want keys %q but got %q", want, got)
+	}
+	if d, ok := restored.TTL("z"); !ok || d != time.Hour {
+		t.Fatalf("want the expiration of z to be kept but got %v %v", d, ok)
+	}
+
+	if err := json.Unmarshal([]byte(`{}`), restored); err == nil {
+		t.Fatal("want an error for an invalid input")
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New[string, int]()
+	c.Set("a", 1)
+	c.Set("b", 2, cache.WithExpiration(time.Minute))
+	c.Set("c", 3, cache.WithExpiration(-time.Second))
+
+	snapshot := c.Snapshot()
+	c.Set("a", 10)
+	if len(snapshot) != 2 {
+		t.Fatalf("want 2 live items but got %v", snapshot)
+	}
+	if got := snapshot["a"]; got.Value != 1 || !got.Expiration.IsZero() {
+		t.Fatalf("want the snapshot not to be affected by Set, but got %+v", got)
+	}
+	if got := snapshot["b"]; got.Value != 2 || !got.Expiration.Equal(now.Add(time.Minute)) {
+		t.Fatalf("want b with the expiration, but got %+v", got)
+	}
+}
+
+func TestClone(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(3)))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	c.Get("a")
+
+	clone := c.Clone(cache.AsLRU[string, int](lru.WithCapacity(3)))
+	defer clone.Close()
+	c.Delete("a")
+	if got, want := strings.Join(clone.Keys(), ","), "b,c,a"; got != want {
+		t.Fatalf("want keys %q but got %q", want, got)
+	}
+
+	// the eviction order is kept.
+	clone.Set("d", 4)
+	if _, ok := clone.Get("b"); ok {
+		t.Fatal("want b to be evicted from the clone")
+	}
+}
+
+func TestSetItems(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	c := cache.New(cache.WithDefaultExpiration[string, int](time.Minute))
+	c.SetItems([]cache.Item[string, int]{
+		{Key: "a", Value: 1, Expiration: now.Add(time.Hour)},
+		{Key: "b", Value: 2},
+		{Key: "c", Value: 3, Expiration: now.Add(-time.Second)},
+	})
+
+	if _, exp, ok := c.GetWithExpiration("a"); !ok || !exp.Equal(now.Add(time.Hour)) {
+		t.Fatalf("want a to expire in an hour but got %v %v", exp, ok)
+	}
+	if _, exp, ok := c.GetWithExpiration("b"); !ok || !exp.IsZero() {
+		t.Fatalf("want b w/o expiration but got %v %v", exp, ok)
+	}
+	if c.Contains("c") {
+		t.Fatal("want the expired item to be skipped")
+	}
+
+	// restores a snapshot.
+	items := make([]cache.Item[string, int], 0, 2)
+	for _, item := range c.Snapshot() {
+		items = append(items, item)
+	}
+	restored := cache.New[string, int]()
+	restored.SetItems(items)
+	if got := restored.Len(); got != 2 {
+		t.Fatalf("want 2 restored items but got %d", got)
+	}
+}
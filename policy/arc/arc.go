@@ -0,0 +1,299 @@
+package arc
+
+import (
+	"container/list"
+)
+
+// Cache is used an ARC (Adaptive Replacement Cache) cache replacement policy.
+//
+// Keeps items used once (T1) apart from items used more than once (T2), and
+// remembers the keys recently evicted from each of them in ghost lists (B1 and
+// B2). A hit in a ghost list adapts the target size of T1, so the balance
+// between recency and frequency is tuned to the workload without any options.
+// The ghost lists hold only keys, and they are bounded by the capacity.
+type Cache[K comparable, V any] struct {
+	cap    int
+	p      int // target size of T1
+	t1, t2 *list.List
+	b1, b2 *list.List
+	items  map[K]*list.Element
+	ghosts map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key      K
+	val      V
+	frequent bool // in T2 or B2
+}
+
+// Option is an option for ARC cache.
+type Option func(*options)
+
+type options struct {
+	capacity int
+}
+
+func newOptions() *options {
+	return &options{
+		capacity: 128,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// NewCache creates a new non-thread safe ARC cache whose capacity is the default size (128).
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	return &Cache[K, V]{
+		cap:    o.capacity,
+		t1:     list.New(),
+		t2:     list.New(),
+		b1:     list.New(),
+		b2:     list.New(),
+		items:  make(map[K]*list.Element, o.capacity),
+		ghosts: make(map[K]*list.Element, o.capacity),
+	}
+}
+
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.promote(e)
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Peek looks up a key's value from the cache without updating the lists.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// promote moves the item to the most recently used end of T2.
+func (c *Cache[K, V]) promote(e *list.Element) {
+	ent := e.Value.(*entry[K, V])
+	if ent.frequent {
+		c.t2.MoveToFront(e)
+		return
+	}
+	c.t1.Remove(e)
+	ent.frequent = true
+	c.items[ent.key] = c.t2.PushFront(ent)
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// item which has been evicted to make room for the value if any.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).val = val
+		c.promote(e)
+		return
+	}
+	if c.cap <= 0 {
+		// the cache holds no items, so the new item is evicted at once like
+		// the other policies do.
+		return key, val, true
+	}
+
+	if g, ok := c.ghosts[key]; ok {
+		// a hit in a ghost list adapts the target size of T1.
+		ghost := g.Value.(*entry[K, V])
+		if ghost.frequent {
+			c.p = maxInt(c.p-maxInt(1, c.b1.Len()/c.b2.Len()), 0)
+			c.b2.Remove(g)
+		} else {
+			c.p = minInt(c.p+maxInt(1, c.b2.Len()/c.b1.Len()), c.cap)
+			c.b1.Remove(g)
+		}
+		delete(c.ghosts, key)
+		if c.full() {
+			evictedKey, evictedVal, evicted = c.replace(ghost.frequent)
+		}
+		c.items[key] = c.t2.PushFront(&entry[K, V]{key: key, val: val, frequent: true})
+		return
+	}
+
+	if c.t1.Len()+c.b1.Len() >= c.cap {
+		if c.t1.Len() < c.cap {
+			c.forget(c.b1)
+			if c.full() {
+				evictedKey, evictedVal, evicted = c.replace(false)
+			}
+		} else {
+			// T1 fills the cache, so the oldest item is discarded w/o a ghost.
+			ent := c.t1.Remove(c.t1.Back()).(*entry[K, V])
+			delete(c.items, ent.key)
+			evictedKey, evictedVal, evicted = ent.key, ent.val, true
+		}
+	} else if total := c.t1.Len() + c.t2.Len() + c.b1.Len() + c.b2.Len(); total >= c.cap {
+		if total >= 2*c.cap {
+			c.forget(c.b2)
+		}
+		if c.full() {
+			evictedKey, evictedVal, evicted = c.replace(false)
+		}
+	}
+	c.items[key] = c.t1.PushFront(&entry[K, V]{key: key, val: val})
+	return
+}
+
+// full reports whether the cache has no room for a new item.
+func (c *Cache[K, V]) full() bool {
+	return len(c.items) >= c.cap
+}
+
+// replace evicts the least recently used item of T1 or T2 to the ghost list
+// according to the target size of T1, and returns it.
+func (c *Cache[K, V]) replace(inB2 bool) (key K, val V, ok bool) {
+	from, to := c.t2, c.b2
+	if t1 := c.t1.Len(); t1 > 0 && (t1 > c.p || (inB2 && t1 == c.p) || c.t2.Len() == 0) {
+		from, to = c.t1, c.b1
+	}
+	back := from.Back()
+	if back == nil {
+		return
+	}
+	ent := from.Remove(back).(*entry[K, V])
+	delete(c.items, ent.key)
+	c.ghosts[ent.key] = to.PushFront(&entry[K, V]{key: ent.key, frequent: ent.frequent})
+	return ent.key, ent.val, true
+}
+
+// forget removes the least recently evicted key from the ghost list.
+func (c *Cache[K, V]) forget(l *list.List) {
+	if back := l.Back(); back != nil {
+		ghost := l.Remove(back).(*entry[K, V])
+		delete(c.ghosts, ghost.key)
+	}
+}
+
+// Evict removes the item which would be evicted next from the cache and
+// returns it. ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	return c.replace(false)
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first by Evict. An item evicted by Set may differ since a hit in
+// the ghost lists adapts the target size of T1.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	keys := make([]K, 0, len(c.items))
+	t1, t2 := c.t1.Back(), c.t2.Back()
+	for n := c.t1.Len(); t1 != nil || t2 != nil; {
+		if t1 != nil && (n > c.p || t2 == nil) {
+			keys = append(keys, t1.Value.(*entry[K, V]).key)
+			t1 = t1.Prev()
+			n--
+			continue
+		}
+		keys = append(keys, t2.Value.(*entry[K, V]).key)
+		t2 = t2.Prev()
+	}
+	return keys
+}
+
+// Keys returns the keys of the cache. the order is T1 and T2, each from the
+// least recently used.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.List{c.t1, c.t2} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		if e.Value.(*entry[K, V]).frequent {
+			c.t2.Remove(e)
+		} else {
+			c.t1.Remove(e)
+		}
+		delete(c.items, key)
+	}
+	if g, ok := c.ghosts[key]; ok {
+		if g.Value.(*entry[K, V]).frequent {
+			c.b2.Remove(g)
+		} else {
+			c.b1.Remove(g)
+		}
+		delete(c.ghosts, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. The ghost lists are trimmed to fit in n as well.
+// Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	c.cap = n
+	c.p = minInt(c.p, n)
+	for c.t1.Len()+c.b1.Len() > n && c.b1.Len() > 0 {
+		c.forget(c.b1)
+	}
+	for c.t1.Len()+c.t2.Len()+c.b1.Len()+c.b2.Len() > 2*n && c.b2.Len() > 0 {
+		c.forget(c.b2)
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once, and forgets the keys in the
+// ghost lists.
+func (c *Cache[K, V]) Clear() {
+	for _, l := range []*list.List{c.t1, c.t2, c.b1, c.b2} {
+		l.Init()
+	}
+	c.p = 0
+	c.items = make(map[K]*list.Element, c.cap)
+	c.ghosts = make(map[K]*list.Element, c.cap)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,171 @@
+package arc_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gekatateam/go-generics-cache/policy/arc"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1
+	cache := arc.NewCache[string, int](arc.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid eviction foo %v", ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("bar")
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+	cache.Delete("foo")
+	cache.Delete("bar")
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("bar"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := arc.NewCache[string, int]()
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("foo") // moved to T2
+
+	got := strings.Join(cache.Keys(), ",")
+	if want := "bar,baz,foo"; got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
+
+func TestScanResistance(t *testing.T) {
+	cache := arc.NewCache[int, int](arc.WithCapacity(100))
+	for i := 0; i < 50; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+
+	// a scan of keys used only once evicts from T1 first.
+	for i := 1000; i < 11000; i++ {
+		cache.Set(i, i)
+	}
+	if got := cache.Len(); got != 100 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	for i := 0; i < 50; i++ {
+		if _, ok := cache.Peek(i); !ok {
+			t.Fatalf("want the frequently used item %d to be kept", i)
+		}
+	}
+}
+
+func TestAdaptation(t *testing.T) {
+	cache := arc.NewCache[int, int](arc.WithCapacity(4))
+	for i := 0; i < 4; i++ {
+		cache.Set(i, i)
+	}
+	cache.Set(4, 4) // 0 is evicted to the ghost list B1
+	cache.Set(0, 0) // a hit in B1 stores 0 in T2
+	if _, ok := cache.Peek(0); !ok {
+		t.Fatal("want the key in the ghost list to be stored again")
+	}
+	if got := cache.Len(); got != 4 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	keys := cache.Keys()
+	if got := keys[len(keys)-1]; got != 0 {
+		t.Fatalf("want 0 to be the most frequently used, but got keys %v", keys)
+	}
+}
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, _, evicted := cache.SetWithEvicted("b", 20); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if !evicted || key != "a" || val != 1 {
+		t.Fatalf("want a 1 to be evicted, but got %q %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := arc.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	key, val, ok := cache.Evict()
+	if !ok || key != "b" || val != 2 {
+		t.Fatalf("want b 2 to be evicted, but got %q %d %v", key, val, ok)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestZeroCapacity(t *testing.T) {
+	cache := arc.NewCache[string, int](arc.WithCapacity(0))
+	key, val, evicted := cache.SetWithEvicted("a", 1)
+	if !evicted || key != "a" || val != 1 {
+		t.Fatalf("want the new item to be evicted at once, but got %q %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	resized := arc.NewCache[string, int](arc.WithCapacity(2))
+	resized.Set("a", 1)
+	resized.Set("b", 2)
+	resized.Get("a")
+	resized.Resize(0)
+	resized.Set("c", 3)
+	resized.Set("a", 10)
+	if got := resized.Len(); got != 0 {
+		t.Fatalf("want no items after Resize(0) but got %d", got)
+	}
+}
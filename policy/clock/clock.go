@@ -18,6 +18,7 @@ type Cache[K comparable, V any] struct {
 	hand     *ring.Ring
 	head     *ring.Ring
 	capacity int
+	maxRefs  int
 }
 
 type entry[K comparable, V any] struct {
@@ -30,7 +31,8 @@ type entry[K comparable, V any] struct {
 type Option func(*options)
 
 type options struct {
-	capacity int
+	capacity      int
+	referenceBits int
 }
 
 func newOptions() *options {
@@ -46,6 +48,19 @@ func WithCapacity(cap int) Option {
 	}
 }
 
+// WithReferenceBits is an option to limit the reference count of each item to
+// n, so an item is given at most n second chances by the sweeping hand before
+// it is evicted (GCLOCK). 1 is the classic clock with a single reference bit,
+// and a larger n makes the cache behave closer to LRU at the cost of longer
+// sweeps.
+//
+// Default is 0, which does not limit the reference count.
+func WithReferenceBits(n int) Option {
+	return func(o *options) {
+		o.referenceBits = n
+	}
+}
+
 // NewCache creates a new non-thread safe clock cache whose capacity is the default size (128).
 func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 	o := newOptions()
@@ -58,18 +73,27 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 		hand:     r,
 		head:     r,
 		capacity: o.capacity,
+		maxRefs:  o.referenceBits,
 	}
 }
 
 // Set sets any item to the cache. replacing any existing item.
 func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets any item to the cache like Set, and returns the item
+// which the hand pointed to if it has been evicted to make room for the value.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
 	if e, ok := c.items[key]; ok {
 		entry := e.Value.(*entry[K, V])
-		entry.referenceCount++
+		c.reference(entry)
 		entry.val = val
 		return
 	}
-	c.evict()
+	if old := c.evict(); old != nil {
+		evictedKey, evictedVal, evicted = old.key, old.val, true
+	}
 	c.hand.Value = &entry[K, V]{
 		key:            key,
 		val:            val,
@@ -77,6 +101,24 @@ func (c *Cache[K, V]) Set(key K, val V) {
 	}
 	c.items[key] = c.hand
 	c.hand = c.hand.Next()
+	return
+}
+
+// Evict removes the item which the hand points to after sweeping the
+// reference counts, and returns it. ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	if len(c.items) == 0 {
+		return
+	}
+	for {
+		// evict stops at an empty slot, which is left by Delete.
+		for c.hand.Value == nil {
+			c.hand = c.hand.Next()
+		}
+		if old := c.evict(); old != nil {
+			return old.key, old.val, true
+		}
+	}
 }
 
 // Get looks up a key's value from the cache.
@@ -86,20 +128,79 @@ func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
 		return
 	}
 	entry := e.Value.(*entry[K, V])
-	entry.referenceCount++
+	c.reference(entry)
 	return entry.val, true
 }
 
-func (c *Cache[K, V]) evict() {
+// reference increments the reference count of the entry up to the limit.
+func (c *Cache[K, V]) reference(e *entry[K, V]) {
+	if c.maxRefs <= 0 || e.referenceCount < c.maxRefs {
+		e.referenceCount++
+	}
+}
+
+// Hand returns the key of the item which the hand points to. ok is false if
+// the hand points to an empty slot. It is useful for debugging.
+func (c *Cache[K, V]) Hand() (key K, ok bool) {
+	if c.hand.Value == nil {
+		return
+	}
+	return c.hand.Value.(*entry[K, V]).key, true
+}
+
+// Peek looks up a key's value from the cache without updating the reference count.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// evict makes the hand point to an empty slot, and returns the evicted entry if any.
+func (c *Cache[K, V]) evict() *entry[K, V] {
 	for c.hand.Value != nil && c.hand.Value.(*entry[K, V]).referenceCount > 0 {
 		c.hand.Value.(*entry[K, V]).referenceCount--
 		c.hand = c.hand.Next()
 	}
-	if c.hand.Value != nil {
-		entry := c.hand.Value.(*entry[K, V])
-		delete(c.items, entry.key)
-		c.hand.Value = nil
+	if c.hand.Value == nil {
+		return nil
 	}
+	entry := c.hand.Value.(*entry[K, V])
+	delete(c.items, entry.key)
+	c.hand.Value = nil
+	return entry
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first by Evict, simulating the sweeps of the hand w/o updating the
+// reference counts.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	type slot struct {
+		key  K
+		refs int
+	}
+	slots := make([]*slot, 0, len(c.items))
+	for i, p := 0, c.hand; i < c.capacity; i, p = i+1, p.Next() {
+		if p.Value != nil {
+			e := p.Value.(*entry[K, V])
+			slots = append(slots, &slot{key: e.key, refs: e.referenceCount})
+		}
+	}
+	keys := make([]K, 0, len(slots))
+	for i := 0; len(slots) > 0; {
+		if s := slots[i]; s.refs > 0 {
+			s.refs--
+			i = (i + 1) % len(slots)
+			continue
+		}
+		keys = append(keys, slots[i].key)
+		slots = append(slots[:i], slots[i+1:]...)
+		if i == len(slots) {
+			i = 0
+		}
+	}
+	return keys
 }
 
 // Keys returns the keys of the cache. the order as same as current ring order.
@@ -135,3 +236,51 @@ func (c *Cache[K, V]) Delete(key K) {
 func (c *Cache[K, V]) Len() int {
 	return len(c.items)
 }
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Resize changes the capacity of the cache to n, which is at least 1, evicting
+// items as Evict does until the cache fits in it. The ring is rebuilt from the
+// hand keeping the order of items. Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	if n < 1 {
+		n = 1
+	}
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	r := ring.New(n)
+	slot := r
+	copied := 0
+	for i, p := 0, c.hand; i < c.capacity; i, p = i+1, p.Next() {
+		if p.Value == nil {
+			continue
+		}
+		slot.Value = p.Value
+		c.items[p.Value.(*entry[K, V]).key] = slot
+		slot = slot.Next()
+		copied++
+	}
+	c.head = r
+	c.hand = r
+	if copied < n {
+		// points to the first empty slot.
+		c.hand = slot
+	}
+	c.capacity = n
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	r := ring.New(c.capacity)
+	c.hand = r
+	c.head = r
+	c.items = make(map[K]*ring.Ring, c.capacity)
+}
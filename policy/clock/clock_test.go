@@ -150,3 +150,145 @@ func TestIssue29(t *testing.T) {
 		t.Errorf("want keys %q, but got keys %q", wantKeys, gotKeys)
 	}
 }
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, _, evicted := cache.SetWithEvicted("b", 20); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	// all reference bits are cleared while searching, and "a" is at the hand.
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if key != "a" || val != 1 || !evicted {
+		t.Fatalf("want a 1 true, but got %s %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if got, ok := cache.Peek("a"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+	if _, ok := cache.Peek("c"); ok {
+		t.Fatal("want c not to be found")
+	}
+
+	// a is evicted first since Peek does not set its reference bit.
+	key, _, evicted := cache.SetWithEvicted("c", 3)
+	if !evicted || key != "a" {
+		t.Fatalf("want a to be evicted, but got %q %v", key, evicted)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := clock.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	key, val, ok := cache.Evict()
+	if !ok {
+		t.Fatal("want an eviction")
+	}
+	if got, found := cache.Get(key); found {
+		t.Fatalf("want the evicted item %s to be removed, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 2}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(4))
+	for i, key := range []string{"a", "b", "c", "d"} {
+		cache.Set(key, i)
+	}
+	cache.Get("c")
+	cache.Get("d")
+
+	if got := cache.Resize(2); got != 2 {
+		t.Fatalf("want 2 evictions, but got %d", got)
+	}
+	if got := strings.Join(cache.Keys(), ","); got != "c,d" {
+		t.Fatalf("want the referenced items to be kept, but got %q", got)
+	}
+
+	if got := cache.Resize(3); got != 0 {
+		t.Fatalf("want no eviction when growing, but got %d", got)
+	}
+	if _, _, evicted := cache.SetWithEvicted("e", 4); evicted {
+		t.Fatal("want no eviction within the grown capacity")
+	}
+	if got := cache.Len(); got != 3 || cache.Cap() != 3 {
+		t.Fatalf("invalid length %d or capacity %d", got, cache.Cap())
+	}
+	if _, _, evicted := cache.SetWithEvicted("f", 5); !evicted {
+		t.Fatal("want an eviction over the capacity")
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Clear()
+	if got := cache.Len(); got != 0 || len(cache.Keys()) != 0 {
+		t.Fatalf("want an empty cache but got %d items", got)
+	}
+	cache.Set("c", 3)
+	cache.Set("d", 4)
+	if got := strings.Join(cache.Keys(), ","); got != "c,d" {
+		t.Fatalf("want c,d but got %q", got)
+	}
+}
+
+func TestReferenceBits(t *testing.T) {
+	evict := func(bits int) string {
+		cache := clock.NewCache[string, int](
+			clock.WithCapacity(3),
+			clock.WithReferenceBits(bits),
+		)
+		cache.Set("a", 1)
+		cache.Set("b", 2)
+		cache.Set("c", 3)
+		for i := 0; i < 3; i++ {
+			cache.Get("a")
+		}
+		key, _, _ := cache.SetWithEvicted("d", 4)
+		return key
+	}
+
+	// a single bit forgets how many times a has been referenced.
+	if got := evict(1); got != "a" {
+		t.Fatalf("want a to be evicted with 1 bit but got %q", got)
+	}
+	if got := evict(3); got != "b" {
+		t.Fatalf("want b to be evicted with 3 bits but got %q", got)
+	}
+}
+
+func TestHand(t *testing.T) {
+	cache := clock.NewCache[string, int](clock.WithCapacity(2))
+	if _, ok := cache.Hand(); ok {
+		t.Fatal("want the hand to point to an empty slot")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if key, ok := cache.Hand(); !ok || key != "a" {
+		t.Fatalf("want the hand to point to a but got %q %v", key, ok)
+	}
+	cache.Set("c", 3) // sweeps a and b, and evicts a
+	if key, ok := cache.Hand(); !ok || key != "b" {
+		t.Fatalf("want the hand to point to b but got %q %v", key, ok)
+	}
+}
@@ -53,18 +53,60 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 }
 
 // Set sets any item to the cache. replacing any existing item.
+//
+// Replacing an existing item keeps its position in the queue, so it is
+// evicted in the order it was first inserted.
 func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// first inserted item if it has been evicted to make room for the value.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).val = val
+		return
+	}
 	if c.queue.Len() == c.capacity {
-		e := c.dequeue()
-		delete(c.items, e.Value.(*entry[K, V]).key)
+		oldest := c.dequeue().Value.(*entry[K, V])
+		delete(c.items, oldest.key)
+		evictedKey, evictedVal, evicted = oldest.key, oldest.val, true
 	}
-	c.Delete(key) // delete old key if already exists specified key.
 	entry := &entry[K, V]{
 		key: key,
 		val: val,
 	}
 	e := c.queue.PushBack(entry)
 	c.items[key] = e
+	return
+}
+
+// Evict removes the first inserted item from the cache and returns it.
+// ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	if c.queue.Len() == 0 {
+		return
+	}
+	oldest := c.dequeue().Value.(*entry[K, V])
+	delete(c.items, oldest.key)
+	return oldest.key, oldest.val, true
+}
+
+// Oldest returns the first inserted item w/o removing it.
+// ok is false if the cache is empty.
+func (c *Cache[K, V]) Oldest() (key K, val V, ok bool) {
+	e := c.queue.Front()
+	if e == nil {
+		return
+	}
+	oldest := e.Value.(*entry[K, V])
+	return oldest.key, oldest.val, true
+}
+
+// RemoveOldest removes the first inserted item from the cache and returns it
+// like Evict. ok is false if the cache is empty.
+func (c *Cache[K, V]) RemoveOldest() (key K, val V, ok bool) {
+	return c.Evict()
 }
 
 // Get gets an item from the cache.
@@ -77,7 +119,19 @@ func (c *Cache[K, V]) Get(k K) (val V, ok bool) {
 	return got.Value.(*entry[K, V]).val, true
 }
 
-// Keys returns cache keys.
+// Peek looks up a key's value from the cache like Get.
+// The FIFO cache has no state which is updated by Get.
+func (c *Cache[K, V]) Peek(k K) (val V, ok bool) {
+	return c.Get(k)
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first. It is the same as Keys.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	return c.Keys()
+}
+
+// Keys returns cache keys. the order is from first inserted to last inserted.
 func (c *Cache[K, V]) Keys() []K {
 	keys := make([]K, 0, len(c.items))
 	for e := c.queue.Front(); e != nil; e = e.Next() {
@@ -86,6 +140,30 @@ func (c *Cache[K, V]) Keys() []K {
 	return keys
 }
 
+// KeysPage returns at most limit keys of the cache in the order of Keys,
+// skipping the first offset keys, w/o enumerating the rest of them.
+func (c *Cache[K, V]) KeysPage(offset, limit int) []K {
+	if offset < 0 {
+		offset = 0
+	}
+	n := len(c.items) - offset
+	if n > limit {
+		n = limit
+	}
+	if n <= 0 {
+		return []K{}
+	}
+	keys := make([]K, 0, n)
+	for e := c.queue.Front(); e != nil && len(keys) < n; e = e.Next() {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		keys = append(keys, e.Value.(*entry[K, V]).key)
+	}
+	return keys
+}
+
 // Delete deletes the item with provided key from the cache.
 func (c *Cache[K, V]) Delete(key K) {
 	if e, ok := c.items[key]; ok {
@@ -104,3 +182,27 @@ func (c *Cache[K, V]) dequeue() *list.Element {
 	c.queue.Remove(e)
 	return e
 }
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	c.capacity = n
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	c.queue.Init()
+	c.items = make(map[K]*list.Element, c.capacity)
+}
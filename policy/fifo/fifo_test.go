@@ -73,11 +73,12 @@ func TestKeys(t *testing.T) {
 	cache.Set("bar", 4) // again
 	cache.Set("foo", 5) // again
 
+	// overwriting does not change the position.
 	got := strings.Join(cache.Keys(), ",")
 	want := strings.Join([]string{
-		"baz",
-		"bar",
 		"foo",
+		"bar",
+		"baz",
 	}, ",")
 	if got != want {
 		t.Errorf("want %q, but got %q", want, got)
@@ -86,3 +87,86 @@ func TestKeys(t *testing.T) {
 		t.Errorf("want number of keys %d, but got %d", len(cache.Keys()), cache.Len())
 	}
 }
+
+func TestOverwriteKeepsPosition(t *testing.T) {
+	cache := fifo.NewCache[string, int](fifo.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("bar", 3) // overwriting at capacity must not evict
+
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value foo %d, cachehit %v", got, ok)
+	}
+
+	cache.Set("foo", 4) // foo is still the oldest
+	cache.Set("baz", 5)
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatal("want foo to be evicted first")
+	}
+	got := strings.Join(cache.Keys(), ",")
+	if want := "bar,baz"; got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := fifo.NewCache[string, int](fifo.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	if _, _, evicted := cache.SetWithEvicted("a", 10); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	// "a" is the first inserted
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if key != "a" || val != 10 || !evicted {
+		t.Fatalf("want a 10 true, but got %s %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := fifo.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	key, val, ok := cache.Evict()
+	if !ok {
+		t.Fatal("want an eviction")
+	}
+	if got, found := cache.Get(key); found {
+		t.Fatalf("want the evicted item %s to be removed, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 2}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestOldest(t *testing.T) {
+	cache := fifo.NewCache[string, int](fifo.WithCapacity(2))
+	if _, _, ok := cache.Oldest(); ok {
+		t.Fatal("want no oldest item in the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3) // evicts a
+	if key, val, ok := cache.Oldest(); !ok || key != "b" || val != 2 {
+		t.Fatalf("want b 2 but got %v %v %v", key, val, ok)
+	}
+	if key, _, ok := cache.RemoveOldest(); !ok || key != "b" {
+		t.Fatalf("want b to be removed but got %v %v", key, ok)
+	}
+	if key, _, ok := cache.Oldest(); !ok || key != "c" || cache.Len() != 1 {
+		t.Fatalf("want c to be the oldest but got %v %v", key, ok)
+	}
+}
@@ -2,6 +2,8 @@ package lfu
 
 import (
 	"container/heap"
+	"sort"
+	"time"
 )
 
 // Cache is used a LFU (Least-frequently used) cache replacement policy.
@@ -10,10 +12,18 @@ import (
 // This works very similar to LRU except that instead of storing the value of how recently
 // a block was accessed, we store the value of how many times it was accessed. So of course
 // while running an access sequence we will replace a block which was used fewest times from our cache.
+//
+// If values implement Coster, the item with the lowest score of
+// referenceCount * Cost() is evicted instead, so a costly value is retained
+// longer than cheap ones at the same frequency. The cost of other values is 1.
+// Ties are broken by evicting the least recently referenced item.
 type Cache[K comparable, V any] struct {
 	cap   int
 	queue *priorityQueue[K, V]
 	items map[K]*entry[K, V]
+	// halfLife is the period to halve the reference counts if it is positive.
+	halfLife  time.Duration
+	decayedAt time.Time
 }
 
 // Option is an option for LFU cache.
@@ -21,6 +31,7 @@ type Option func(*options)
 
 type options struct {
 	capacity int
+	halfLife time.Duration
 }
 
 func newOptions() *options {
@@ -36,6 +47,18 @@ func WithCapacity(cap int) Option {
 	}
 }
 
+// WithDecay is an option to halve the reference counts of all items every
+// halfLife, so that items which were used frequently long ago eventually
+// become evictable. The counts are decayed lazily by the next access after
+// each period, which takes O(n) time.
+//
+// Default is none, the reference counts never decay.
+func WithDecay(halfLife time.Duration) Option {
+	return func(o *options) {
+		o.halfLife = halfLife
+	}
+}
+
 // NewCache creates a new non-thread safe LFU cache whose capacity is the default size (128).
 func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 	o := newOptions()
@@ -43,25 +66,66 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 		optFunc(o)
 	}
 	return &Cache[K, V]{
-		cap:   o.capacity,
-		queue: newPriorityQueue[K, V](o.capacity),
-		items: make(map[K]*entry[K, V], o.capacity),
+		cap:       o.capacity,
+		queue:     newPriorityQueue[K, V](o.capacity),
+		items:     make(map[K]*entry[K, V], o.capacity),
+		halfLife:  o.halfLife,
+		decayedAt: time.Now(),
 	}
 }
 
+// decay halves the reference counts once for each half-life which has passed
+// since the last decay.
+func (c *Cache[K, V]) decay() {
+	if c.halfLife <= 0 {
+		return
+	}
+	periods := time.Since(c.decayedAt) / c.halfLife
+	if periods == 0 {
+		return
+	}
+	c.decayedAt = c.decayedAt.Add(periods * c.halfLife)
+	shift := uint(periods)
+	if shift > 63 {
+		shift = 63
+	}
+	for _, e := range *c.queue {
+		e.referenceCount >>= shift
+	}
+	// the order of items whose counts become equal may change.
+	heap.Init(c.queue)
+}
+
 // Get looks up a key's value from the cache.
 func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
 	e, ok := c.items[key]
 	if !ok {
 		return
 	}
+	c.decay()
 	e.referenced()
 	heap.Fix(c.queue, e.index)
 	return e.val, true
 }
 
+// Peek looks up a key's value from the cache without updating the reference count.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.val, true
+}
+
 // Set sets a value to the cache with key. replacing any existing value.
 func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// least frequently used item if it has been evicted to make room for the value.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	c.decay()
 	if e, ok := c.items[key]; ok {
 		c.queue.update(e, val)
 		return
@@ -70,14 +134,44 @@ func (c *Cache[K, V]) Set(key K, val V) {
 	if len(c.items) == c.cap {
 		evictedEntry := heap.Pop(c.queue).(*entry[K, V])
 		delete(c.items, evictedEntry.key)
+		evictedKey, evictedVal, evicted = evictedEntry.key, evictedEntry.val, true
 	}
 
 	e := newEntry(key, val)
 	heap.Push(c.queue, e)
 	c.items[key] = e
+	return
+}
+
+// Evict removes the least frequently used item from the cache and returns it.
+// ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	if len(c.items) == 0 {
+		return
+	}
+	c.decay()
+	evictedEntry := heap.Pop(c.queue).(*entry[K, V])
+	delete(c.items, evictedEntry.key)
+	return evictedEntry.key, evictedEntry.val, true
 }
 
-// Keys returns the keys of the cache. the order is from oldest to newest.
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first, which has the lowest score. The decay of WithDecay which is
+// due is taken into account.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	c.decay()
+	queue := make(priorityQueue[K, V], len(*c.queue))
+	copy(queue, *c.queue)
+	sort.Slice(queue, queue.Less)
+	keys := make([]K, 0, len(queue))
+	for _, e := range queue {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Keys returns the keys of the cache. the order is the order of the internal
+// heap, which is not specified. Use KeysInEvictionOrder for the sorted keys.
 func (c *Cache[K, V]) Keys() []K {
 	keys := make([]K, 0, len(c.items))
 	for _, entry := range *c.queue {
@@ -98,3 +192,37 @@ func (c *Cache[K, V]) Delete(key K) {
 func (c *Cache[K, V]) Len() int {
 	return c.queue.Len()
 }
+
+// FrequencyBuckets returns the distribution of access frequencies, mapping each
+// frequency to the number of items which are currently at that frequency.
+func (c *Cache[K, V]) FrequencyBuckets() map[uint]int {
+	buckets := make(map[uint]int)
+	for _, entry := range *c.queue {
+		buckets[uint(entry.referenceCount)]++
+	}
+	return buckets
+}
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	c.cap = n
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	c.queue = newPriorityQueue[K, V](c.cap)
+	c.items = make(map[K]*entry[K, V], c.cap)
+}
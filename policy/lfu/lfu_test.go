@@ -2,6 +2,7 @@ package lfu_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/gekatateam/go-generics-cache/policy/lfu"
 )
@@ -63,3 +64,119 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("invalid get after deleted %v", ok)
 	}
 }
+
+func TestFrequencyBuckets(t *testing.T) {
+	cache := lfu.NewCache[string, int]()
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("bar")
+	cache.Get("baz")
+	cache.Get("baz")
+
+	got := cache.FrequencyBuckets()
+	want := map[uint]int{1: 1, 2: 1, 3: 1}
+	if len(got) != len(want) {
+		t.Fatalf("want %v, but got %v", want, got)
+	}
+	for freq, n := range want {
+		if got[freq] != n {
+			t.Errorf("want %d items at frequency %d, but got %d", n, freq, got[freq])
+		}
+	}
+}
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	if _, _, evicted := cache.SetWithEvicted("a", 10); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	// "b" is the least frequently used
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if key != "b" || val != 2 || !evicted {
+		t.Fatalf("want b 2 true, but got %s %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	cache := lfu.NewCache[string, int]()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if got, ok := cache.Peek("a"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+	if _, ok := cache.Peek("c"); ok {
+		t.Fatal("want c not to be found")
+	}
+	if got := cache.FrequencyBuckets(); len(got) != 1 || got[1] != 2 {
+		t.Fatalf("want the frequencies to be kept, but got %v", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := lfu.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	key, val, ok := cache.Evict()
+	if !ok {
+		t.Fatal("want an eviction")
+	}
+	if got, found := cache.Get(key); found {
+		t.Fatalf("want the evicted item %s to be removed, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 2}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestDecay(t *testing.T) {
+	cache := lfu.NewCache[string, int](lfu.WithCapacity(2), lfu.WithDecay(10*time.Millisecond))
+	cache.Set("old", 1)
+	for i := 0; i < 3; i++ {
+		cache.Get("old")
+	}
+
+	// old has been referenced 4 times, and its count is halved to 0 after 3
+	// half-lives, so the count of the new item is higher.
+	time.Sleep(30 * time.Millisecond)
+	cache.Set("new", 2)
+	cache.Get("new")
+	cache.Set("newer", 3)
+	if _, ok := cache.Peek("old"); ok {
+		t.Fatal("want the decayed item to be evicted")
+	}
+	if _, ok := cache.Peek("new"); !ok {
+		t.Fatal("want the recently used item to be kept")
+	}
+}
+
+type costly int64
+
+func (c costly) Cost() int64 { return int64(c) }
+
+func TestCost(t *testing.T) {
+	cache := lfu.NewCache[string, costly](lfu.WithCapacity(2))
+	cache.Set("expensive", 10)
+	cache.Set("cheap", 1)
+	cache.Get("cheap") // score 2 < 10
+
+	cache.Set("new", 1)
+	if _, ok := cache.Peek("cheap"); ok {
+		t.Fatal("want the cheap item to be evicted")
+	}
+	if _, ok := cache.Peek("expensive"); !ok {
+		t.Fatal("want the expensive item to be retained")
+	}
+}
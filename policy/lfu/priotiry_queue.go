@@ -11,6 +11,21 @@ type entry[K comparable, V any] struct {
 	val            V
 	referenceCount int
 	referencedAt   time.Time
+	cost           int64
+}
+
+// Coster is an optional interface of values which have the cost to be
+// recomputed. The LFU cache retains costly values longer (see Cache).
+type Coster interface {
+	Cost() int64
+}
+
+// costOf returns the cost of the value, which is at least 1.
+func costOf[V any](val V) int64 {
+	if c, ok := any(val).(Coster); ok && c.Cost() > 1 {
+		return c.Cost()
+	}
+	return 1
 }
 
 func newEntry[K comparable, V any](key K, val V) *entry[K, V] {
@@ -20,9 +35,15 @@ func newEntry[K comparable, V any](key K, val V) *entry[K, V] {
 		val:            val,
 		referenceCount: 1,
 		referencedAt:   time.Now(),
+		cost:           costOf(val),
 	}
 }
 
+// score is the priority of the entry, which is evicted from the lowest.
+func (e *entry[K, V]) score() int64 {
+	return int64(e.referenceCount) * e.cost
+}
+
 func (e *entry[K, V]) referenced() {
 	e.referenceCount++
 	e.referencedAt = time.Now()
@@ -41,16 +62,16 @@ var _ heap.Interface = (*priorityQueue[struct{}, interface{}])(nil)
 func (l priorityQueue[K, V]) Len() int { return len(l) }
 
 func (l priorityQueue[K, V]) Less(i, j int) bool {
-	if l[i].referenceCount == l[j].referenceCount {
-		return l[i].referencedAt.Before(l[j].referencedAt)
+	if si, sj := l[i].score(), l[j].score(); si != sj {
+		return si < sj
 	}
-	return l[i].referenceCount < l[j].referenceCount
+	return l[i].referencedAt.Before(l[j].referencedAt)
 }
 
 func (l priorityQueue[K, V]) Swap(i, j int) {
 	l[i], l[j] = l[j], l[i]
 	l[i].index = i
-	l[i].index = j
+	l[j].index = j
 }
 
 func (l *priorityQueue[K, V]) Push(x interface{}) {
@@ -71,6 +92,7 @@ func (l *priorityQueue[K, V]) Pop() interface{} {
 
 func (pq *priorityQueue[K, V]) update(e *entry[K, V], val V) {
 	e.val = val
+	e.cost = costOf(val)
 	e.referenced()
 	heap.Fix(pq, e.index)
 }
@@ -2,6 +2,8 @@ package lru
 
 import (
 	"container/list"
+	"sort"
+	"time"
 )
 
 // Cache is used a LRU (Least recently used) cache replacement policy.
@@ -9,10 +11,20 @@ import (
 // Discards the least recently used items first. This algorithm requires
 // keeping track of what was used when, which is expensive if one wants
 // to make sure the algorithm always discards the least recently used item.
+//
+// With WithExpiryAwareEviction, the item which expires first is discarded
+// instead if values implement Expirer.
 type Cache[K comparable, V any] struct {
-	cap   int
-	list  *list.List
-	items map[K]*list.Element
+	cap         int
+	list        *list.List
+	items       map[K]*list.Element
+	expiryAware bool
+}
+
+// Expirer is an optional interface of values which expire at some time. The
+// zero time means the value never expires.
+type Expirer interface {
+	ExpiresAt() time.Time
 }
 
 type entry[K comparable, V any] struct {
@@ -24,7 +36,8 @@ type entry[K comparable, V any] struct {
 type Option func(*options)
 
 type options struct {
-	capacity int
+	capacity    int
+	expiryAware bool
 }
 
 func newOptions() *options {
@@ -40,6 +53,19 @@ func WithCapacity(cap int) Option {
 	}
 }
 
+// WithExpiryAwareEviction is an option to evict the item which expires first
+// instead of the least recently used item when the cache is full, so capacity
+// is not wasted on items which are about to expire. Items which never expire
+// are evicted only if there is no other item, and ties are broken by evicting
+// the least recently used one.
+//
+// Eviction scans all items, so it takes O(n) time.
+func WithExpiryAwareEviction() Option {
+	return func(o *options) {
+		o.expiryAware = true
+	}
+}
+
 // NewCache creates a new non-thread safe LRU cache whose capacity is the default size (128).
 func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 	o := newOptions()
@@ -47,9 +73,10 @@ func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
 		optFunc(o)
 	}
 	return &Cache[K, V]{
-		cap:   o.capacity,
-		list:  list.New(),
-		items: make(map[K]*list.Element, o.capacity),
+		cap:         o.capacity,
+		list:        list.New(),
+		items:       make(map[K]*list.Element, o.capacity),
+		expiryAware: o.expiryAware,
 	}
 }
 
@@ -64,8 +91,23 @@ func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
 	return e.Value.(*entry[K, V]).val, true
 }
 
+// Peek looks up a key's value from the cache without updating the cache order.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
 // Set sets a value to the cache with key. replacing any existing value.
 func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// least recently used item if it has been evicted to make room for the value.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
 	if e, ok := c.items[key]; ok {
 		// updates cache order
 		c.list.MoveToFront(e)
@@ -82,11 +124,91 @@ func (c *Cache[K, V]) Set(key K, val V) {
 	c.items[key] = e
 
 	if c.list.Len() > c.cap {
-		c.deleteOldest()
+		return c.Evict()
+	}
+	return
+}
+
+// Evict removes the least recently used item, or the item which expires first
+// with WithExpiryAwareEviction, from the cache and returns it. ok is false if
+// the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	e := c.victim()
+	if e == nil {
+		return
+	}
+	c.delete(e)
+	victim := e.Value.(*entry[K, V])
+	return victim.key, victim.val, true
+}
+
+// victim returns the element which would be evicted next.
+func (c *Cache[K, V]) victim() *list.Element {
+	if !c.expiryAware {
+		return c.list.Back()
+	}
+	victim := c.list.Back()
+	var earliest time.Time
+	for e := victim; e != nil; e = e.Prev() {
+		exp, ok := any(e.Value.(*entry[K, V]).val).(Expirer)
+		if !ok {
+			continue
+		}
+		if at := exp.ExpiresAt(); !at.IsZero() && (earliest.IsZero() || at.Before(earliest)) {
+			victim, earliest = e, at
+		}
+	}
+	return victim
+}
+
+// PeekOldest returns the least recently used item w/o removing it or updating
+// the cache order. ok is false if the cache is empty.
+func (c *Cache[K, V]) PeekOldest() (key K, val V, ok bool) {
+	e := c.list.Back()
+	if e == nil {
+		return
+	}
+	oldest := e.Value.(*entry[K, V])
+	return oldest.key, oldest.val, true
+}
+
+// RemoveOldest removes the least recently used item from the cache and
+// returns it, even with WithExpiryAwareEviction. ok is false if the cache is
+// empty.
+func (c *Cache[K, V]) RemoveOldest() (key K, val V, ok bool) {
+	if c.list.Len() == 0 {
+		return
 	}
+	oldest := c.deleteOldest()
+	return oldest.key, oldest.val, true
 }
 
-// Keys returns the keys of the cache. the order is from oldest to newest.
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first. It is the same as Keys unless WithExpiryAwareEviction is set.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	keys := c.Keys()
+	if !c.expiryAware {
+		return keys
+	}
+	expiresAt := func(key K) time.Time {
+		if exp, ok := any(c.items[key].Value.(*entry[K, V]).val).(Expirer); ok {
+			return exp.ExpiresAt()
+		}
+		return time.Time{}
+	}
+	// keeps the order of ties from the least recently used as victim does.
+	sort.SliceStable(keys, func(i, j int) bool {
+		ti, tj := expiresAt(keys[i]), expiresAt(keys[j])
+		if ti.IsZero() || tj.IsZero() {
+			return !ti.IsZero() && tj.IsZero()
+		}
+		return ti.Before(tj)
+	})
+	return keys
+}
+
+// Keys returns the keys of the cache. the order is from the least recently
+// used to the most recently used.
 func (c *Cache[K, V]) Keys() []K {
 	keys := make([]K, 0, len(c.items))
 	for ent := c.list.Back(); ent != nil; ent = ent.Prev() {
@@ -96,6 +218,30 @@ func (c *Cache[K, V]) Keys() []K {
 	return keys
 }
 
+// KeysPage returns at most limit keys of the cache in the order of Keys,
+// skipping the first offset keys, w/o enumerating the rest of them.
+func (c *Cache[K, V]) KeysPage(offset, limit int) []K {
+	if offset < 0 {
+		offset = 0
+	}
+	n := len(c.items) - offset
+	if n > limit {
+		n = limit
+	}
+	if n <= 0 {
+		return []K{}
+	}
+	keys := make([]K, 0, n)
+	for e := c.list.Back(); e != nil && len(keys) < n; e = e.Prev() {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		keys = append(keys, e.Value.(*entry[K, V]).key)
+	}
+	return keys
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache[K, V]) Len() int {
 	return c.list.Len()
@@ -108,9 +254,10 @@ func (c *Cache[K, V]) Delete(key K) {
 	}
 }
 
-func (c *Cache[K, V]) deleteOldest() {
+func (c *Cache[K, V]) deleteOldest() *entry[K, V] {
 	e := c.list.Back()
 	c.delete(e)
+	return e.Value.(*entry[K, V])
 }
 
 func (c *Cache[K, V]) delete(e *list.Element) {
@@ -118,3 +265,27 @@ func (c *Cache[K, V]) delete(e *list.Element) {
 	entry := e.Value.(*entry[K, V])
 	delete(c.items, entry.key)
 }
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	c.cap = n
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	c.list.Init()
+	c.items = make(map[K]*list.Element, c.cap)
+}
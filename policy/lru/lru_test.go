@@ -1,7 +1,9 @@
 package lru_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gekatateam/go-generics-cache/policy/lru"
 )
@@ -63,3 +65,146 @@ func TestDelete(t *testing.T) {
 		t.Fatalf("invalid get after deleted %v", ok)
 	}
 }
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	if _, _, evicted := cache.SetWithEvicted("a", 10); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	// "b" is the least recently used
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if key != "b" || val != 2 || !evicted {
+		t.Fatalf("want b 2 true, but got %s %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if got, ok := cache.Peek("a"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+	if _, ok := cache.Peek("c"); ok {
+		t.Fatal("want c not to be found")
+	}
+
+	// a is still the least recently used item.
+	key, _, evicted := cache.SetWithEvicted("c", 3)
+	if !evicted || key != "a" {
+		t.Fatalf("want a to be evicted, but got %q %v", key, evicted)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := lru.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	key, val, ok := cache.Evict()
+	if !ok {
+		t.Fatal("want an eviction")
+	}
+	if got, found := cache.Get(key); found {
+		t.Fatalf("want the evicted item %s to be removed, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 2}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(3))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.Get("a")
+
+	if got := cache.Resize(2); got != 1 {
+		t.Fatalf("want 1 eviction, but got %d", got)
+	}
+	if _, ok := cache.Peek("b"); ok {
+		t.Fatal("want the least recently used item to be evicted")
+	}
+	if got := cache.Resize(4); got != 0 || cache.Cap() != 4 {
+		t.Fatalf("want no eviction when growing, but got %d", got)
+	}
+}
+
+func TestClear(t *testing.T) {
+	cache := lru.NewCache[string, int](lru.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Clear()
+	if got := cache.Len(); got != 0 || len(cache.Keys()) != 0 {
+		t.Fatalf("want an empty cache but got %d items", got)
+	}
+	cache.Set("c", 3)
+	if _, ok := cache.Get("c"); !ok {
+		t.Fatal("want the cache to be usable after Clear")
+	}
+}
+
+func TestRemoveOldest(t *testing.T) {
+	cache := lru.NewCache[string, int]()
+	if _, _, ok := cache.PeekOldest(); ok {
+		t.Fatal("want no oldest item in the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3)
+	cache.Get("a")
+
+	if key, val, ok := cache.PeekOldest(); !ok || key != "b" || val != 2 {
+		t.Fatalf("want b 2 but got %v %v %v", key, val, ok)
+	}
+	// PeekOldest does not update the cache order.
+	if key, _, ok := cache.RemoveOldest(); !ok || key != "b" {
+		t.Fatalf("want b to be removed but got %v %v", key, ok)
+	}
+	if key, _, ok := cache.PeekOldest(); !ok || key != "c" || cache.Len() != 2 {
+		t.Fatalf("want c to be the oldest but got %v %v", key, ok)
+	}
+}
+
+type expiring struct{ at time.Time }
+
+func (e expiring) ExpiresAt() time.Time { return e.at }
+
+func TestExpiryAwareEviction(t *testing.T) {
+	now := time.Now()
+	cache := lru.NewCache[string, expiring](
+		lru.WithCapacity(3),
+		lru.WithExpiryAwareEviction(),
+	)
+	cache.Set("never", expiring{})
+	cache.Set("late", expiring{now.Add(time.Hour)})
+	cache.Set("soon", expiring{now.Add(time.Minute)})
+
+	if key, _, ok := cache.SetWithEvicted("new", expiring{now.Add(2 * time.Hour)}); !ok || key != "soon" {
+		t.Fatalf("want soon to be evicted but got %q %v", key, ok)
+	}
+	if got := strings.Join(cache.KeysInEvictionOrder(), ","); got != "late,new,never" {
+		t.Fatalf("want the eviction order late,new,never but got %q", got)
+	}
+	if key, _, ok := cache.Evict(); !ok || key != "late" {
+		t.Fatalf("want late to be evicted but got %q %v", key, ok)
+	}
+	if key, _, ok := cache.Evict(); !ok || key != "new" {
+		t.Fatalf("want new to be evicted but got %q %v", key, ok)
+	}
+	if key, _, ok := cache.Evict(); !ok || key != "never" {
+		t.Fatalf("want never to be evicted at last but got %q %v", key, ok)
+	}
+}
@@ -0,0 +1,23 @@
+package lruk_test
+
+import (
+	"fmt"
+
+	"github.com/gekatateam/go-generics-cache/policy/lruk"
+)
+
+func ExampleNewCache() {
+	c := lruk.NewCache[string, int](2)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	av, aok := c.Get("a")
+	bv, bok := c.Get("b")
+	cv, cok := c.Get("c")
+	fmt.Println(av, aok)
+	fmt.Println(bv, bok)
+	fmt.Println(cv, cok)
+	// Output:
+	// 1 true
+	// 2 true
+	// 0 false
+}
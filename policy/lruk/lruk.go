@@ -0,0 +1,177 @@
+package lruk
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Cache is used a LRU-K cache replacement policy.
+//
+// Discards the item whose kth most recent access is the oldest, which is the
+// item with the largest backward k-distance. Items which have been accessed
+// less than k times have infinite distance, and the least recently used one
+// of them is discarded first. So items used only once, such as by a burst of
+// unique lookups, do not evict items which are accessed repeatedly.
+// LRU-1 is the same as LRU.
+type Cache[K comparable, V any] struct {
+	k     int
+	cap   int
+	time  uint64
+	queue *priorityQueue[K, V]
+	items map[K]*entry[K, V]
+}
+
+// Option is an option for LRU-K cache.
+type Option func(*options)
+
+type options struct {
+	capacity int
+}
+
+func newOptions() *options {
+	return &options{
+		capacity: 128,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// NewCache creates a new non-thread safe LRU-K cache whose capacity is the default size (128).
+// k is the number of access times which are tracked for each item, and it is at least 1.
+func NewCache[K comparable, V any](k int, opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	if k < 1 {
+		k = 1
+	}
+	return &Cache[K, V]{
+		k:     k,
+		cap:   o.capacity,
+		queue: newPriorityQueue[K, V](o.capacity),
+		items: make(map[K]*entry[K, V], o.capacity),
+	}
+}
+
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.reference(e)
+	return e.val, true
+}
+
+// Peek looks up a key's value from the cache without recording the access.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.val, true
+}
+
+// reference records an access to the entry.
+func (c *Cache[K, V]) reference(e *entry[K, V]) {
+	c.time++
+	e.referenced(c.time)
+	heap.Fix(c.queue, e.index)
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// item with the largest backward k-distance if it has been evicted to make
+// room for the value.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	if e, ok := c.items[key]; ok {
+		e.val = val
+		c.reference(e)
+		return
+	}
+	if len(c.items) >= c.cap {
+		evictedKey, evictedVal, evicted = c.Evict()
+	}
+	e := newEntry(key, val, c.k)
+	c.time++
+	e.referenced(c.time)
+	heap.Push(c.queue, e)
+	c.items[key] = e
+	return
+}
+
+// Evict removes the item with the largest backward k-distance from the cache
+// and returns it. ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	if len(c.items) == 0 {
+		return
+	}
+	e := heap.Pop(c.queue).(*entry[K, V])
+	delete(c.items, e.key)
+	return e.key, e.val, true
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first. It is the same as Keys.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	return c.Keys()
+}
+
+// Keys returns the keys of the cache. the order is from the item to be evicted first.
+func (c *Cache[K, V]) Keys() []K {
+	queue := make(priorityQueue[K, V], len(*c.queue))
+	copy(queue, *c.queue)
+	sort.Slice(queue, queue.Less)
+	keys := make([]K, 0, len(queue))
+	for _, e := range queue {
+		keys = append(keys, e.key)
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		heap.Remove(c.queue, e.index)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	c.cap = n
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	c.queue = newPriorityQueue[K, V](c.cap)
+	c.items = make(map[K]*entry[K, V], c.cap)
+}
@@ -0,0 +1,142 @@
+package lruk_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+	"github.com/gekatateam/go-generics-cache/policy/lruk"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1
+	cache := lruk.NewCache[string, int](2, lruk.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid eviction foo %v", ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := lruk.NewCache[string, int](2, lruk.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+	cache.Delete("foo")
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := lruk.NewCache[string, int](2)
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("foo")
+
+	got := strings.Join(cache.Keys(), ",")
+	if want := "bar,baz,foo"; got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
+
+func TestBurstOfUniqueLookups(t *testing.T) {
+	type policy interface {
+		Get(key string) (int, bool)
+		Set(key string, val int)
+	}
+	burst := func(cache policy) bool {
+		cache.Set("hot", 1)
+		cache.Get("hot")
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			cache.Set(key, 0)
+		}
+		_, ok := cache.Get("hot")
+		return ok
+	}
+
+	if burst(lru.NewCache[string, int](lru.WithCapacity(3))) {
+		t.Fatal("want plain LRU to evict the hot key")
+	}
+	if !burst(lruk.NewCache[string, int](2, lruk.WithCapacity(3))) {
+		t.Fatal("want LRU-2 to keep the hot key")
+	}
+}
+
+func TestLRU1(t *testing.T) {
+	cache := lruk.NewCache[string, int](1, lruk.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	cache.Set("c", 3)
+	if _, ok := cache.Peek("b"); ok {
+		t.Fatal("want LRU-1 to evict the least recently used item")
+	}
+}
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := lruk.NewCache[string, int](2, lruk.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, _, evicted := cache.SetWithEvicted("b", 20); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if !evicted || key != "a" || val != 1 {
+		t.Fatalf("want a 1 to be evicted, but got %q %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := lruk.NewCache[string, int](2)
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	key, val, ok := cache.Evict()
+	if !ok || key != "b" || val != 2 {
+		t.Fatalf("want b 2 to be evicted, but got %q %d %v", key, val, ok)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
@@ -0,0 +1,91 @@
+package lruk
+
+import (
+	"container/heap"
+)
+
+type entry[K comparable, V any] struct {
+	index int
+	key   K
+	val   V
+	// history is a ring buffer of the last k access times.
+	history []uint64
+	// next is the position in history which the next access time is written to.
+	next int
+	// accesses is the number of accesses, which is up to k.
+	accesses int
+}
+
+func newEntry[K comparable, V any](key K, val V, k int) *entry[K, V] {
+	return &entry[K, V]{
+		key:     key,
+		val:     val,
+		history: make([]uint64, k),
+	}
+}
+
+// referenced records an access at the time.
+func (e *entry[K, V]) referenced(time uint64) {
+	e.history[e.next] = time
+	e.next = (e.next + 1) % len(e.history)
+	if e.accesses < len(e.history) {
+		e.accesses++
+	}
+}
+
+// kth returns the time of the kth most recent access. It is 0 if the entry
+// has been accessed less than k times, which means the backward k-distance is infinite.
+func (e *entry[K, V]) kth() uint64 {
+	if e.accesses < len(e.history) {
+		return 0
+	}
+	return e.history[e.next]
+}
+
+// last returns the time of the most recent access.
+func (e *entry[K, V]) last() uint64 {
+	return e.history[(e.next+len(e.history)-1)%len(e.history)]
+}
+
+type priorityQueue[K comparable, V any] []*entry[K, V]
+
+func newPriorityQueue[K comparable, V any](cap int) *priorityQueue[K, V] {
+	queue := make(priorityQueue[K, V], 0, cap)
+	return &queue
+}
+
+// see example of priority queue: https://pkg.go.dev/container/heap
+var _ heap.Interface = (*priorityQueue[struct{}, interface{}])(nil)
+
+func (l priorityQueue[K, V]) Len() int { return len(l) }
+
+// Less orders entries by the largest backward k-distance first, and by the
+// least recently used first among the same distance.
+func (l priorityQueue[K, V]) Less(i, j int) bool {
+	if ki, kj := l[i].kth(), l[j].kth(); ki != kj {
+		return ki < kj
+	}
+	return l[i].last() < l[j].last()
+}
+
+func (l priorityQueue[K, V]) Swap(i, j int) {
+	l[i], l[j] = l[j], l[i]
+	l[i].index = i
+	l[j].index = j
+}
+
+func (l *priorityQueue[K, V]) Push(x interface{}) {
+	entry := x.(*entry[K, V])
+	entry.index = len(*l)
+	*l = append(*l, entry)
+}
+
+func (l *priorityQueue[K, V]) Pop() interface{} {
+	old := *l
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil   // avoid memory leak
+	entry.index = -1 // for safety
+	*l = old[0 : n-1]
+	return entry
+}
@@ -62,8 +62,23 @@ func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
 	return e.Value.(*entry[K, V]).val, true
 }
 
+// Peek looks up a key's value from the cache without updating the cache order.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
 // Set sets a value to the cache with key. replacing any existing value.
 func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// most recently used item if it has been evicted to make room for the value.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
 	if e, ok := c.items[key]; ok {
 		// updates cache order
 		c.list.MoveToBack(e)
@@ -73,7 +88,8 @@ func (c *Cache[K, V]) Set(key K, val V) {
 	}
 
 	if c.list.Len() == c.cap {
-		c.deleteNewest()
+		newest := c.deleteNewest()
+		evictedKey, evictedVal, evicted = newest.key, newest.val, true
 	}
 
 	newEntry := &entry[K, V]{
@@ -82,6 +98,27 @@ func (c *Cache[K, V]) Set(key K, val V) {
 	}
 	e := c.list.PushBack(newEntry)
 	c.items[key] = e
+	return
+}
+
+// Evict removes the item which would be evicted next by Set from the cache and
+// returns it. ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	if c.list.Len() == 0 {
+		return
+	}
+	newest := c.deleteNewest()
+	return newest.key, newest.val, true
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first. It is the reverse of Keys.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	keys := make([]K, 0, len(c.items))
+	for ent := c.list.Front(); ent != nil; ent = ent.Next() {
+		keys = append(keys, ent.Value.(*entry[K, V]).key)
+	}
+	return keys
 }
 
 // Keys returns the keys of the cache. the order is from recently used.
@@ -94,6 +131,30 @@ func (c *Cache[K, V]) Keys() []K {
 	return keys
 }
 
+// KeysPage returns at most limit keys of the cache in the order of Keys,
+// skipping the first offset keys, w/o enumerating the rest of them.
+func (c *Cache[K, V]) KeysPage(offset, limit int) []K {
+	if offset < 0 {
+		offset = 0
+	}
+	n := len(c.items) - offset
+	if n > limit {
+		n = limit
+	}
+	if n <= 0 {
+		return []K{}
+	}
+	keys := make([]K, 0, n)
+	for e := c.list.Back(); e != nil && len(keys) < n; e = e.Prev() {
+		if offset > 0 {
+			offset--
+			continue
+		}
+		keys = append(keys, e.Value.(*entry[K, V]).key)
+	}
+	return keys
+}
+
 // Len returns the number of items in the cache.
 func (c *Cache[K, V]) Len() int {
 	return c.list.Len()
@@ -106,9 +167,10 @@ func (c *Cache[K, V]) Delete(key K) {
 	}
 }
 
-func (c *Cache[K, V]) deleteNewest() {
+func (c *Cache[K, V]) deleteNewest() *entry[K, V] {
 	e := c.list.Front()
 	c.delete(e)
+	return e.Value.(*entry[K, V])
 }
 
 func (c *Cache[K, V]) delete(e *list.Element) {
@@ -116,3 +178,27 @@ func (c *Cache[K, V]) delete(e *list.Element) {
 	entry := e.Value.(*entry[K, V])
 	delete(c.items, entry.key)
 }
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	c.cap = n
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	c.list.Init()
+	c.items = make(map[K]*list.Element, c.cap)
+}
@@ -91,3 +91,63 @@ func TestKeys(t *testing.T) {
 		t.Errorf("want number of keys %d, but got %d", len(cache.Keys()), cache.Len())
 	}
 }
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := mru.NewCache[string, int](mru.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, _, evicted := cache.SetWithEvicted("b", 20); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if !evicted {
+		t.Fatal("want an eviction over the cap")
+	}
+	if got, ok := cache.Get(key); ok {
+		t.Fatalf("want the reported item %s to be evicted, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 20}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	cache := mru.NewCache[string, int]()
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	want := strings.Join(cache.Keys(), ",")
+	if got, ok := cache.Peek("a"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+	if _, ok := cache.Peek("c"); ok {
+		t.Fatal("want c not to be found")
+	}
+	if got := strings.Join(cache.Keys(), ","); got != want {
+		t.Errorf("want the order %q to be kept, but got %q", want, got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := mru.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	key, val, ok := cache.Evict()
+	if !ok {
+		t.Fatal("want an eviction")
+	}
+	if got, found := cache.Get(key); found {
+		t.Fatalf("want the evicted item %s to be removed, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 2}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
@@ -1,34 +1,135 @@
 package simple
 
 import (
+	"container/list"
 	"sort"
 	"time"
 )
 
 // Cache is a simple cache has no clear priority for evict cache.
 type Cache[K comparable, V any] struct {
-	items map[K]*entry[V]
+	items     map[K]*entry[V]
+	order     *list.List // keys from the oldest inserted one
+	capacity  int
+	reject    bool
+	softLimit int
+	onExceed  func(size int)
 }
 
 type entry[V any] struct {
 	val       V
 	createdAt time.Time
+	elem      *list.Element
+}
+
+// Option is an option for simple cache.
+type Option func(*options)
+
+type options struct {
+	capacity  int
+	reject    bool
+	softLimit int
+	onExceed  func(size int)
+}
+
+func newOptions() *options {
+	return &options{}
+}
+
+// WithCapacity is an option to set cache capacity.
+//
+// When the cache is full, the oldest inserted item is evicted to make room for
+// a new item, unless WithRejectWhenFull is specified. Default is 0 which means
+// the cache is unbounded.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// WithRejectWhenFull is an option to refuse to insert a new item instead of
+// evicting the oldest one when the cache is full. Replacing an existing item
+// is always allowed.
+func WithRejectWhenFull() Option {
+	return func(o *options) {
+		o.reject = true
+	}
+}
+
+// WithSoftLimit is an option to be notified about unexpected growth of the cache.
+//
+// The cache never evicts any items, but onExceed is called with the current
+// size each time the number of items crosses over n.
+func WithSoftLimit(n int, onExceed func(size int)) Option {
+	return func(o *options) {
+		o.softLimit = n
+		o.onExceed = onExceed
+	}
 }
 
 // NewCache creates a new non-thread safe cache.
-func NewCache[K comparable, V any]() *Cache[K, V] {
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
 	return &Cache[K, V]{
-		items: make(map[K]*entry[V], 0),
+		items:     make(map[K]*entry[V], 0),
+		order:     list.New(),
+		capacity:  o.capacity,
+		reject:    o.reject,
+		softLimit: o.softLimit,
+		onExceed:  o.onExceed,
 	}
 }
 
 // Set sets any item to the cache. replacing any existing item.
 // The default item never expires.
 func (c *Cache[K, V]) Set(k K, v V) {
+	c.SetWithEvicted(k, v)
+}
+
+// SetWithEvicted sets any item to the cache like Set, and returns the oldest
+// inserted item if it has been evicted to make room for the value.
+func (c *Cache[K, V]) SetWithEvicted(k K, v V) (evictedKey K, evictedVal V, evicted bool) {
+	before := len(c.items)
+	if e, ok := c.items[k]; ok {
+		c.order.MoveToBack(e.elem)
+		e.val = v
+		e.createdAt = time.Now()
+		return
+	}
+	if c.capacity > 0 && len(c.items) >= c.capacity {
+		if c.reject {
+			return
+		}
+		oldest := c.order.Front()
+		evictedKey = oldest.Value.(K)
+		evictedVal, evicted = c.items[evictedKey].val, true
+		c.Delete(evictedKey)
+	}
 	c.items[k] = &entry[V]{
 		val:       v,
 		createdAt: time.Now(),
+		elem:      c.order.PushBack(k),
 	}
+	if c.onExceed != nil && before == c.softLimit && len(c.items) > c.softLimit {
+		c.onExceed(len(c.items))
+	}
+	return
+}
+
+// Evict removes the oldest inserted item from the cache and returns it.
+// ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	oldest := c.order.Front()
+	if oldest == nil {
+		return
+	}
+	key = oldest.Value.(K)
+	val = c.items[key].val
+	c.Delete(key)
+	return key, val, true
 }
 
 // Get gets an item from the cache.
@@ -41,6 +142,12 @@ func (c *Cache[K, V]) Get(k K) (val V, ok bool) {
 	return got.val, true
 }
 
+// Peek looks up a key's value from the cache like Get.
+// The simple cache has no state which is updated by Get.
+func (c *Cache[K, V]) Peek(k K) (val V, ok bool) {
+	return c.Get(k)
+}
+
 // Keys returns cache keys. the order is sorted by created.
 func (c *Cache[K, _]) Keys() []K {
 	ret := make([]K, 0, len(c.items))
@@ -55,7 +162,50 @@ func (c *Cache[K, _]) Keys() []K {
 	return ret
 }
 
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first, which is the first created item.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	keys := make([]K, 0, len(c.items))
+	for e := c.order.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(K))
+	}
+	return keys
+}
+
 // Delete deletes the item with provided key from the cache.
 func (c *Cache[K, V]) Delete(key K) {
-	delete(c.items, key)
+	if e, ok := c.items[key]; ok {
+		c.order.Remove(e.elem)
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Cap returns the capacity of the cache. 0 means the cache is unbounded.
+func (c *Cache[K, V]) Cap() int {
+	return c.capacity
+}
+
+// Resize changes the capacity of the cache to n, evicting the oldest inserted
+// items until the cache fits in it. 0 makes the cache unbounded.
+// Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	c.capacity = n
+	for n > 0 && c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	c.items = make(map[K]*entry[V], 0)
+	c.order.Init()
 }
@@ -0,0 +1,97 @@
+package simple_test
+
+import (
+	"testing"
+
+	"github.com/gekatateam/go-generics-cache/policy/simple"
+)
+
+func TestSoftLimit(t *testing.T) {
+	var exceeded []int
+	cache := simple.NewCache[string, int](
+		simple.WithSoftLimit(2, func(size int) { exceeded = append(exceeded, size) }),
+	)
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	if len(exceeded) != 0 {
+		t.Fatalf("want no call within the soft limit, but got %v", exceeded)
+	}
+
+	cache.Set("baz", 3)
+	cache.Set("baz", 4) // replacing does not cross the limit
+	cache.Set("qux", 5) // already over the limit
+	if len(exceeded) != 1 || exceeded[0] != 3 {
+		t.Fatalf("want a call with size 3, but got %v", exceeded)
+	}
+	if got := cache.Len(); got != 4 {
+		t.Fatalf("want all items are kept, but got %d", got)
+	}
+
+	// crosses again after shrinking
+	cache.Delete("qux")
+	cache.Delete("baz")
+	cache.Set("baz", 6)
+	if len(exceeded) != 2 {
+		t.Fatalf("want a second call after crossing again, but got %v", exceeded)
+	}
+}
+
+func TestCapacity(t *testing.T) {
+	cache := simple.NewCache[string, int](simple.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("foo", 3) // replacing makes foo the newest
+	key, val, evicted := cache.SetWithEvicted("baz", 4)
+	if !evicted || key != "bar" || val != 2 {
+		t.Fatalf("want bar 2 to be evicted, but got %q %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("want exactly one eviction, but got length %d", got)
+	}
+	if _, ok := cache.Get("bar"); ok {
+		t.Fatal("want bar to be evicted")
+	}
+	for _, key := range []string{"foo", "baz"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Fatalf("want %s to be kept", key)
+		}
+	}
+}
+
+func TestRejectWhenFull(t *testing.T) {
+	cache := simple.NewCache[string, int](simple.WithCapacity(2), simple.WithRejectWhenFull())
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	if _, _, evicted := cache.SetWithEvicted("baz", 3); evicted {
+		t.Fatal("want no eviction")
+	}
+	if _, ok := cache.Get("baz"); ok {
+		t.Fatal("want baz to be rejected")
+	}
+	cache.Set("foo", 4)
+	if got, ok := cache.Get("foo"); !ok || got != 4 {
+		t.Fatalf("want replacing to be allowed, but got %d %v", got, ok)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := simple.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	key, val, ok := cache.Evict()
+	if !ok {
+		t.Fatal("want an eviction")
+	}
+	if got, found := cache.Get(key); found {
+		t.Fatalf("want the evicted item %s to be removed, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 2}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
@@ -0,0 +1,23 @@
+package slru_test
+
+import (
+	"fmt"
+
+	"github.com/gekatateam/go-generics-cache/policy/slru"
+)
+
+func ExampleNewCache() {
+	c := slru.NewCache[string, int](2, 8)
+	c.Set("a", 1)
+	c.Set("b", 2)
+	av, aok := c.Get("a")
+	bv, bok := c.Get("b")
+	cv, cok := c.Get("c")
+	fmt.Println(av, aok)
+	fmt.Println(bv, bok)
+	fmt.Println(cv, cok)
+	// Output:
+	// 1 true
+	// 2 true
+	// 0 false
+}
@@ -0,0 +1,210 @@
+package slru
+
+import (
+	"container/list"
+)
+
+// Cache is used a SLRU (Segmented LRU) cache replacement policy.
+//
+// New items are stored in the probationary segment, and graduate to the
+// protected segment on their second access. When the protected segment is
+// full, its least recently used item is demoted back to the probationary
+// segment, and items are evicted only from the probationary segment while it
+// has any. So items used only once do not evict items accessed repeatedly.
+type Cache[K comparable, V any] struct {
+	probationCap int
+	protectedCap int
+	probation    *list.List
+	protected    *list.List
+	items        map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	val       V
+	protected bool
+}
+
+// NewCache creates a new non-thread safe SLRU cache whose capacity is the sum
+// of the sizes of the probationary segment and the protected segment.
+// The probationary segment has at least one item.
+func NewCache[K comparable, V any](probationSize, protectedSize int) *Cache[K, V] {
+	if probationSize < 1 {
+		probationSize = 1
+	}
+	return &Cache[K, V]{
+		probationCap: probationSize,
+		protectedCap: protectedSize,
+		probation:    list.New(),
+		protected:    list.New(),
+		items:        make(map[K]*list.Element, probationSize+protectedSize),
+	}
+}
+
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	c.access(e)
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Peek looks up a key's value from the cache without updating the segments.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// access moves the item to the most recently used end of the protected
+// segment, demoting the least recently used protected item if it is full.
+func (c *Cache[K, V]) access(e *list.Element) {
+	ent := e.Value.(*entry[K, V])
+	if ent.protected {
+		c.protected.MoveToFront(e)
+		return
+	}
+	if c.protectedCap <= 0 {
+		c.probation.MoveToFront(e)
+		return
+	}
+	c.probation.Remove(e)
+	ent.protected = true
+	c.items[ent.key] = c.protected.PushFront(ent)
+	if c.protected.Len() > c.protectedCap {
+		demoted := c.protected.Remove(c.protected.Back()).(*entry[K, V])
+		demoted.protected = false
+		c.items[demoted.key] = c.probation.PushFront(demoted)
+	}
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// least recently used probationary item if it has been evicted to make room
+// for the value.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).val = val
+		c.access(e)
+		return
+	}
+	if c.probation.Len() >= c.probationCap {
+		evictedKey, evictedVal, evicted = c.Evict()
+	}
+	c.items[key] = c.probation.PushFront(&entry[K, V]{key: key, val: val})
+	return
+}
+
+// Evict removes the least recently used item of the probationary segment, or
+// of the protected segment if the probationary one is empty, and returns it.
+// ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	l := c.probation
+	if l.Len() == 0 {
+		l = c.protected
+	}
+	back := l.Back()
+	if back == nil {
+		return
+	}
+	ent := l.Remove(back).(*entry[K, V])
+	delete(c.items, ent.key)
+	return ent.key, ent.val, true
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first. It is the same as Keys.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	return c.Keys()
+}
+
+// Keys returns the keys of the cache. the order is the probationary segment
+// and the protected segment, each from the least recently used.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.List{c.probation, c.protected} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		if e.Value.(*entry[K, V]).protected {
+			c.protected.Remove(e)
+		} else {
+			c.probation.Remove(e)
+		}
+		delete(c.items, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.probationCap + c.protectedCap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. The sizes of the segments are changed in the same
+// proportion, and the probationary segment has at least one item. Items which
+// overflow one of the segments are moved to the other one.
+// Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	probationCap := 1
+	if total := c.Cap(); total > 0 {
+		probationCap = n * c.probationCap / total
+	}
+	if probationCap < 1 {
+		probationCap = 1
+	}
+	c.probationCap = probationCap
+	c.protectedCap = n - probationCap
+	if c.protectedCap < 0 {
+		c.protectedCap = 0
+	}
+	for c.protected.Len() > c.protectedCap {
+		demoted := c.protected.Remove(c.protected.Back()).(*entry[K, V])
+		demoted.protected = false
+		c.items[demoted.key] = c.probation.PushFront(demoted)
+	}
+	for c.probation.Len() > c.probationCap && c.protected.Len() < c.protectedCap {
+		// promotes the most recently used probationary item to keep it.
+		promoted := c.probation.Remove(c.probation.Front()).(*entry[K, V])
+		promoted.protected = true
+		c.items[promoted.key] = c.protected.PushBack(promoted)
+	}
+	for c.probation.Len() > c.probationCap {
+		c.Evict()
+		evicted++
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once.
+func (c *Cache[K, V]) Clear() {
+	c.probation.Init()
+	c.protected.Init()
+	c.items = make(map[K]*list.Element, c.probationCap+c.protectedCap)
+}
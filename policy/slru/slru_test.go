@@ -0,0 +1,152 @@
+package slru_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gekatateam/go-generics-cache/policy/slru"
+)
+
+func TestSet(t *testing.T) {
+	cache := slru.NewCache[string, int](1, 1)
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	// if over the probationary segment
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if _, ok := cache.Peek("foo"); ok {
+		t.Fatal("want foo to be evicted from the probationary segment")
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := slru.NewCache[string, int](2, 2)
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("bar")
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+	cache.Delete("foo")
+	cache.Delete("bar")
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("bar"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+}
+
+func TestPromotion(t *testing.T) {
+	cache := slru.NewCache[string, int](2, 1)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a") // a graduates to the protected segment
+	if got, want := strings.Join(cache.Keys(), ","), "b,a"; got != want {
+		t.Fatalf("want %q, but got %q", want, got)
+	}
+
+	cache.Get("b") // b graduates and a is demoted
+	if got, want := strings.Join(cache.Keys(), ","), "a,b"; got != want {
+		t.Fatalf("want %q, but got %q", want, got)
+	}
+}
+
+func TestProtectedSurvivesChurn(t *testing.T) {
+	cache := slru.NewCache[int, int](2, 2)
+	cache.Set(0, 0)
+	cache.Set(1, 1)
+	cache.Get(0)
+	cache.Get(1)
+
+	for i := 100; i < 200; i++ {
+		cache.Set(i, i)
+	}
+	if got := cache.Len(); got != 4 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok := cache.Peek(i); !ok {
+			t.Fatalf("want the protected item %d to survive", i)
+		}
+	}
+}
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := slru.NewCache[string, int](2, 2)
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, _, evicted := cache.SetWithEvicted("b", 20); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	if _, _, evicted := cache.SetWithEvicted("c", 3); evicted {
+		t.Fatal("want no eviction since b has graduated")
+	}
+	key, val, evicted := cache.SetWithEvicted("d", 4)
+	if !evicted || key != "a" || val != 1 {
+		t.Fatalf("want a 1 to be evicted, but got %q %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 3 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := slru.NewCache[string, int](2, 2)
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	key, val, ok := cache.Evict()
+	if !ok || key != "b" || val != 2 {
+		t.Fatalf("want b 2 to be evicted, but got %q %d %v", key, val, ok)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestResize(t *testing.T) {
+	cache := slru.NewCache[int, int](2, 2)
+	cache.Set(0, 0)
+	cache.Set(1, 1)
+	cache.Get(0)
+	cache.Get(1)
+	cache.Set(2, 2)
+	cache.Set(3, 3)
+
+	if got := cache.Resize(2); got != 2 {
+		t.Fatalf("want 2 evictions, but got %d", got)
+	}
+	if got := strings.Join(func() []string {
+		var keys []string
+		for _, k := range cache.Keys() {
+			keys = append(keys, string(rune('0'+k)))
+		}
+		return keys
+	}(), ","); got != "0,1" {
+		t.Fatalf("want the protected items to be kept, but got %q", got)
+	}
+	if got := cache.Cap(); got != 2 {
+		t.Fatalf("invalid capacity: %d", got)
+	}
+}
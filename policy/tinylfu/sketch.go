@@ -0,0 +1,73 @@
+package tinylfu
+
+// maxCount is the maximum value of each counter of the sketch.
+const maxCount = 15
+
+// depth is the number of rows of the sketch.
+const depth = 4
+
+// sketch is a count-min sketch which estimates access frequencies of keys.
+//
+// All counters are halved when the number of increments reaches sampleSize,
+// so that old frequent items are aged out.
+type sketch struct {
+	rows       [depth][]uint8
+	mask       uint64
+	additions  int
+	sampleSize int
+}
+
+func newSketch(capacity int) *sketch {
+	width := 16
+	for width < capacity {
+		width <<= 1
+	}
+	s := &sketch{
+		mask:       uint64(width - 1),
+		sampleSize: 10 * capacity,
+	}
+	for i := range s.rows {
+		s.rows[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// index returns the index of the counter in the i-th row by double hashing.
+func (s *sketch) index(h uint64, i int) uint64 {
+	return (h + uint64(i)*(h>>32|1)) & s.mask
+}
+
+// increment increments the counters of the hashed key.
+func (s *sketch) increment(h uint64) {
+	for i := range s.rows {
+		idx := s.index(h, i)
+		if s.rows[i][idx] < maxCount {
+			s.rows[i][idx]++
+		}
+	}
+	s.additions++
+	if s.additions >= s.sampleSize {
+		s.reset()
+	}
+}
+
+// estimate returns the estimated frequency of the hashed key.
+func (s *sketch) estimate(h uint64) uint8 {
+	min := uint8(maxCount)
+	for i := range s.rows {
+		if c := s.rows[i][s.index(h, i)]; c < min {
+			min = c
+		}
+	}
+	return min
+}
+
+// reset halves all counters.
+func (s *sketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] >>= 1
+		}
+	}
+	s.additions /= 2
+}
@@ -0,0 +1,295 @@
+package tinylfu
+
+import (
+	"container/list"
+
+	"github.com/gekatateam/go-generics-cache/internal/hash"
+)
+
+// region is the region of the cache which an item belongs to.
+type region uint8
+
+const (
+	window region = iota
+	probation
+	protected
+)
+
+// Cache is used a W-TinyLFU (Window Tiny Least frequently used) cache replacement policy.
+//
+// New items are stored in a small LRU admission window. An item which is
+// evicted from the window is admitted to the main SLRU region only if it is
+// estimated to be used more frequently than the item which would be evicted
+// from the main region instead. The frequencies are estimated by a count-min
+// sketch which is aged periodically, so that one-hit-wonders do not pollute
+// the cache and old frequent items are eventually discarded.
+type Cache[K comparable, V any] struct {
+	cap           int
+	windowPercent int
+	windowCap     int
+	protectedCap  int
+	lists         [3]*list.List
+	items         map[K]*list.Element
+	sketch        *sketch
+}
+
+type entry[K comparable, V any] struct {
+	key    K
+	val    V
+	hash   uint64
+	region region
+}
+
+// Option is an option for W-TinyLFU cache.
+type Option func(*options)
+
+type options struct {
+	capacity      int
+	windowPercent int
+}
+
+func newOptions() *options {
+	return &options{
+		capacity:      128,
+		windowPercent: 1,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// WithWindowPercent is an option to set the size of the admission window as
+// a percentage of the capacity. The window has at least one item.
+//
+// Default is 1 percent. A larger window favors recency over frequency.
+func WithWindowPercent(percent int) Option {
+	return func(o *options) {
+		o.windowPercent = percent
+	}
+}
+
+// NewCache creates a new non-thread safe W-TinyLFU cache whose capacity is the default size (128).
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	c := &Cache[K, V]{
+		windowPercent: o.windowPercent,
+		items:         make(map[K]*list.Element, o.capacity),
+		sketch:        newSketch(o.capacity),
+	}
+	for i := range c.lists {
+		c.lists[i] = list.New()
+	}
+	c.setCapacity(o.capacity)
+	return c
+}
+
+// setCapacity sets the capacity and the sizes of the regions.
+func (c *Cache[K, V]) setCapacity(cap int) {
+	c.cap = cap
+	c.windowCap = cap * c.windowPercent / 100
+	if c.windowCap < 1 {
+		c.windowCap = 1
+	}
+	c.protectedCap = (cap - c.windowCap) * 80 / 100
+}
+
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		c.sketch.increment(hash.Key(key))
+		return
+	}
+	c.access(e)
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Peek looks up a key's value from the cache without recording the access.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// access records the access to the item, and promotes it from the probation
+// region to the protected region.
+func (c *Cache[K, V]) access(e *list.Element) {
+	ent := e.Value.(*entry[K, V])
+	c.sketch.increment(ent.hash)
+	if ent.region != probation {
+		c.lists[ent.region].MoveToFront(e)
+		return
+	}
+	c.lists[probation].Remove(e)
+	ent.region = protected
+	c.items[ent.key] = c.lists[protected].PushFront(ent)
+	if c.lists[protected].Len() > c.protectedCap {
+		// demotes the least recently used protected item.
+		back := c.lists[protected].Back()
+		demoted := c.lists[protected].Remove(back).(*entry[K, V])
+		demoted.region = probation
+		c.items[demoted.key] = c.lists[probation].PushFront(demoted)
+	}
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns
+// the item which has been evicted to make room for the value if any. It may
+// be the item evicted from the window if it has been refused to be admitted.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	if e, ok := c.items[key]; ok {
+		e.Value.(*entry[K, V]).val = val
+		c.access(e)
+		return
+	}
+
+	h := hash.Key(key)
+	c.sketch.increment(h)
+	c.items[key] = c.lists[window].PushFront(&entry[K, V]{
+		key:    key,
+		val:    val,
+		hash:   h,
+		region: window,
+	})
+	if c.lists[window].Len() <= c.windowCap {
+		return
+	}
+
+	// moves the least recently used item of the window to the main region.
+	candidate := c.lists[window].Remove(c.lists[window].Back()).(*entry[K, V])
+	if c.lists[probation].Len()+c.lists[protected].Len() >= c.cap-c.windowCap {
+		victim := c.victim()
+		if victim == nil || c.sketch.estimate(candidate.hash) <= c.sketch.estimate(victim.hash) {
+			delete(c.items, candidate.key)
+			return candidate.key, candidate.val, true
+		}
+		c.remove(c.items[victim.key])
+		evictedKey, evictedVal, evicted = victim.key, victim.val, true
+	}
+	candidate.region = probation
+	c.items[candidate.key] = c.lists[probation].PushFront(candidate)
+	return
+}
+
+// victim returns the item which would be evicted from the main region.
+func (c *Cache[K, V]) victim() *entry[K, V] {
+	for _, r := range []region{probation, protected} {
+		if back := c.lists[r].Back(); back != nil {
+			return back.Value.(*entry[K, V])
+		}
+	}
+	return nil
+}
+
+// Evict removes the item which would be evicted next from the cache and
+// returns it. ok is false if the cache is empty.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	victim := c.victim()
+	if victim == nil {
+		back := c.lists[window].Back()
+		if back == nil {
+			return
+		}
+		victim = back.Value.(*entry[K, V])
+	}
+	c.remove(c.items[victim.key])
+	return victim.key, victim.val, true
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first by Evict, which is the probation region, the protected region
+// and the window, each from the least recently used. An item evicted by Set
+// may differ since it depends on the admission of the new item.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, r := range []region{probation, protected, window} {
+		for e := c.lists[r].Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Keys returns the keys of the cache. the order is the window, the probation
+// region and the protected region, each from the least recently used.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range c.lists {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		c.remove(e)
+	}
+}
+
+func (c *Cache[K, V]) remove(e *list.Element) {
+	entry := e.Value.(*entry[K, V])
+	c.lists[entry.region].Remove(e)
+	delete(c.items, entry.key)
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. The sizes of the regions are changed in the same
+// proportion, moving items which overflow them to the probation region.
+// Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	c.setCapacity(n)
+	for _, r := range []region{window, protected} {
+		max := c.windowCap
+		if r == protected {
+			max = c.protectedCap
+		}
+		for c.lists[r].Len() > max {
+			moved := c.lists[r].Remove(c.lists[r].Back()).(*entry[K, V])
+			moved.region = probation
+			c.items[moved.key] = c.lists[probation].PushFront(moved)
+		}
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once, and resets the frequencies
+// of the sketch.
+func (c *Cache[K, V]) Clear() {
+	for _, l := range c.lists {
+		l.Init()
+	}
+	c.items = make(map[K]*list.Element, c.cap)
+	c.sketch = newSketch(c.cap)
+}
@@ -0,0 +1,173 @@
+package tinylfu_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/gekatateam/go-generics-cache/policy/lfu"
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+	"github.com/gekatateam/go-generics-cache/policy/tinylfu"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1, which is only the window
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+
+	cache.Delete("foo")
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+	if got := strings.Join(cache.Keys(), ","); got != "bar" {
+		t.Fatalf("want keys %q, but got %q", "bar", got)
+	}
+}
+
+func TestAdmission(t *testing.T) {
+	cache := tinylfu.NewCache[int, int](tinylfu.WithCapacity(100))
+	for i := 0; i < 50; i++ {
+		cache.Set(i, i)
+	}
+	for n := 0; n < 5; n++ {
+		for i := 0; i < 50; i++ {
+			cache.Get(i)
+		}
+	}
+
+	// a scan of one-hit-wonders must not flush the frequently used items.
+	for i := 1000; i < 2000; i++ {
+		cache.Set(i, i)
+	}
+	if got := cache.Len(); got != 100 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	kept := 0
+	for i := 0; i < 50; i++ {
+		if _, ok := cache.Peek(i); ok {
+			kept++
+		}
+	}
+	if kept < 45 {
+		t.Fatalf("want the frequently used items to be kept, but only %d of 50 are", kept)
+	}
+}
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := tinylfu.NewCache[string, int](tinylfu.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, _, evicted := cache.SetWithEvicted("b", 20); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if !evicted {
+		t.Fatal("want an eviction over the cap")
+	}
+	if got, ok := cache.Get(key); ok {
+		t.Fatalf("want the reported item %s to be evicted, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 20, "c": 3}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := tinylfu.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	key, val, ok := cache.Evict()
+	if !ok {
+		t.Fatal("want an eviction")
+	}
+	if got, found := cache.Get(key); found {
+		t.Fatalf("want the evicted item %s to be removed, but got %d", key, got)
+	}
+	if want := map[string]int{"a": 1, "b": 2}[key]; val != want {
+		t.Fatalf("want the value of %s to be %d, but got %d", key, want, val)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+type policy interface {
+	Get(key uint64) (uint64, bool)
+	Set(key, val uint64)
+}
+
+// benchmarkHitRatio reports the hit ratio of the policy on a Zipf distributed trace.
+func benchmarkHitRatio(b *testing.B, cache policy) {
+	zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.01, 1, 1<<16)
+	hits := 0
+	for i := 0; i < b.N; i++ {
+		key := zipf.Uint64()
+		if _, ok := cache.Get(key); ok {
+			hits++
+			continue
+		}
+		cache.Set(key, key)
+	}
+	b.ReportMetric(float64(hits)/float64(b.N), "hits/op")
+}
+
+func BenchmarkHitRatio(b *testing.B) {
+	b.Run("tinylfu", func(b *testing.B) {
+		benchmarkHitRatio(b, tinylfu.NewCache[uint64, uint64](tinylfu.WithCapacity(1000)))
+	})
+	b.Run("lru", func(b *testing.B) {
+		benchmarkHitRatio(b, lru.NewCache[uint64, uint64](lru.WithCapacity(1000)))
+	})
+	b.Run("lfu", func(b *testing.B) {
+		benchmarkHitRatio(b, lfu.NewCache[uint64, uint64](lfu.WithCapacity(1000)))
+	})
+}
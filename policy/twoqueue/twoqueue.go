@@ -0,0 +1,285 @@
+package twoqueue
+
+import (
+	"container/list"
+)
+
+// Cache is used a 2Q cache replacement policy.
+//
+// New items are stored in a FIFO queue for recently used items (A1in), and
+// are promoted to a LRU queue for frequently used items (Am) when they are
+// accessed again. Keys evicted from the recent queue are remembered in a ghost
+// queue (A1out) for a while, and they are stored directly in the frequent queue
+// if they are set again. So items which are used only once, such as by a scan
+// over a large range of keys, do not flush frequently used items.
+type Cache[K comparable, V any] struct {
+	cap         int
+	recentRatio float64
+	ghostRatio  float64
+	recentCap   int
+	ghostCap    int
+	recent      *list.List // A1in, from the newest
+	frequent    *list.List // Am, from the most recently used
+	ghost       *list.List // A1out, keys from the newest
+	items       map[K]*list.Element
+	ghosts      map[K]*list.Element
+}
+
+type entry[K comparable, V any] struct {
+	key      K
+	val      V
+	frequent bool
+}
+
+// Option is an option for 2Q cache.
+type Option func(*options)
+
+type options struct {
+	capacity    int
+	recentRatio float64
+	ghostRatio  float64
+}
+
+func newOptions() *options {
+	return &options{
+		capacity:    128,
+		recentRatio: 0.25,
+		ghostRatio:  0.5,
+	}
+}
+
+// WithCapacity is an option to set cache capacity.
+func WithCapacity(cap int) Option {
+	return func(o *options) {
+		o.capacity = cap
+	}
+}
+
+// WithRecentRatio is an option to set the size of the recent queue as a ratio
+// of the capacity. Items are evicted from the recent queue while it is larger
+// than the size, which is at least 1.
+//
+// Default is 0.25.
+func WithRecentRatio(ratio float64) Option {
+	return func(o *options) {
+		o.recentRatio = ratio
+	}
+}
+
+// WithGhostRatio is an option to set the number of keys which are remembered
+// after evicted from the recent queue as a ratio of the capacity.
+//
+// Default is 0.5.
+func WithGhostRatio(ratio float64) Option {
+	return func(o *options) {
+		o.ghostRatio = ratio
+	}
+}
+
+// NewCache creates a new non-thread safe 2Q cache whose capacity is the default size (128).
+func NewCache[K comparable, V any](opts ...Option) *Cache[K, V] {
+	o := newOptions()
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	c := &Cache[K, V]{
+		recentRatio: o.recentRatio,
+		ghostRatio:  o.ghostRatio,
+		recent:      list.New(),
+		frequent:    list.New(),
+		ghost:       list.New(),
+		items:       make(map[K]*list.Element, o.capacity),
+		ghosts:      make(map[K]*list.Element),
+	}
+	c.setCapacity(o.capacity)
+	return c
+}
+
+// setCapacity sets the capacity and the sizes of the queues.
+func (c *Cache[K, V]) setCapacity(cap int) {
+	c.cap = cap
+	c.recentCap = int(float64(cap) * c.recentRatio)
+	if c.recentCap < 1 {
+		// a new item must not be evicted right after it is stored.
+		c.recentCap = 1
+	}
+	c.ghostCap = int(float64(cap) * c.ghostRatio)
+}
+
+// Get looks up a key's value from the cache.
+func (c *Cache[K, V]) Get(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	ent := e.Value.(*entry[K, V])
+	if ent.frequent {
+		c.frequent.MoveToFront(e)
+	} else {
+		// promotes the item which is accessed again.
+		c.recent.Remove(e)
+		ent.frequent = true
+		c.items[key] = c.frequent.PushFront(ent)
+	}
+	return ent.val, true
+}
+
+// Peek looks up a key's value from the cache without updating the queues.
+func (c *Cache[K, V]) Peek(key K) (zero V, _ bool) {
+	e, ok := c.items[key]
+	if !ok {
+		return
+	}
+	return e.Value.(*entry[K, V]).val, true
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+func (c *Cache[K, V]) Set(key K, val V) {
+	c.SetWithEvicted(key, val)
+}
+
+// SetWithEvicted sets a value to the cache with key like Set, and returns the
+// item which has been evicted to make room for the value if any.
+func (c *Cache[K, V]) SetWithEvicted(key K, val V) (evictedKey K, evictedVal V, evicted bool) {
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*entry[K, V])
+		ent.val = val
+		if ent.frequent {
+			c.frequent.MoveToFront(e)
+		}
+		return
+	}
+
+	ent := &entry[K, V]{key: key, val: val}
+	if g, ok := c.ghosts[key]; ok {
+		// the key has been evicted from the recent queue recently.
+		c.ghost.Remove(g)
+		delete(c.ghosts, key)
+		ent.frequent = true
+		c.items[key] = c.frequent.PushFront(ent)
+	} else {
+		c.items[key] = c.recent.PushFront(ent)
+	}
+
+	if len(c.items) > c.cap {
+		return c.Evict()
+	}
+	return
+}
+
+// Evict removes the item which would be evicted next from the cache and
+// returns it. ok is false if the cache is empty.
+//
+// The oldest item in the recent queue is evicted if the queue is over its
+// size or the frequent queue is empty, and its key is remembered in the ghost
+// queue. Otherwise the least recently used item in the frequent queue is evicted.
+func (c *Cache[K, V]) Evict() (key K, val V, ok bool) {
+	if c.recent.Len() > 0 && (c.recent.Len() > c.recentCap || c.frequent.Len() == 0) {
+		ent := c.recent.Remove(c.recent.Back()).(*entry[K, V])
+		delete(c.items, ent.key)
+		c.remember(ent.key)
+		return ent.key, ent.val, true
+	}
+	if back := c.frequent.Back(); back != nil {
+		ent := c.frequent.Remove(back).(*entry[K, V])
+		delete(c.items, ent.key)
+		return ent.key, ent.val, true
+	}
+	return
+}
+
+// remember adds the key to the ghost queue, forgetting the oldest one if it is full.
+func (c *Cache[K, V]) remember(key K) {
+	if c.ghostCap <= 0 {
+		return
+	}
+	c.ghosts[key] = c.ghost.PushFront(key)
+	if c.ghost.Len() > c.ghostCap {
+		oldest := c.ghost.Remove(c.ghost.Back()).(K)
+		delete(c.ghosts, oldest)
+	}
+}
+
+// KeysInEvictionOrder returns the keys of the cache from the item to be
+// evicted first by Evict.
+func (c *Cache[K, V]) KeysInEvictionOrder() []K {
+	keys := make([]K, 0, len(c.items))
+	recent, frequent := c.recent.Back(), c.frequent.Back()
+	for n := c.recent.Len(); recent != nil || frequent != nil; {
+		if recent != nil && (n > c.recentCap || frequent == nil) {
+			keys = append(keys, recent.Value.(*entry[K, V]).key)
+			recent = recent.Prev()
+			n--
+			continue
+		}
+		keys = append(keys, frequent.Value.(*entry[K, V]).key)
+		frequent = frequent.Prev()
+	}
+	return keys
+}
+
+// Keys returns the keys of the cache. the order is the recent queue and the
+// frequent queue, each from the oldest.
+func (c *Cache[K, V]) Keys() []K {
+	keys := make([]K, 0, len(c.items))
+	for _, l := range []*list.List{c.recent, c.frequent} {
+		for e := l.Back(); e != nil; e = e.Prev() {
+			keys = append(keys, e.Value.(*entry[K, V]).key)
+		}
+	}
+	return keys
+}
+
+// Delete deletes the item with provided key from the cache.
+func (c *Cache[K, V]) Delete(key K) {
+	if e, ok := c.items[key]; ok {
+		if e.Value.(*entry[K, V]).frequent {
+			c.frequent.Remove(e)
+		} else {
+			c.recent.Remove(e)
+		}
+		delete(c.items, key)
+	}
+	if g, ok := c.ghosts[key]; ok {
+		c.ghost.Remove(g)
+		delete(c.ghosts, key)
+	}
+}
+
+// Len returns the number of items in the cache.
+func (c *Cache[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Cap returns the capacity of the cache.
+func (c *Cache[K, V]) Cap() int {
+	return c.cap
+}
+
+// Resize changes the capacity of the cache to n, evicting items as Evict does
+// until the cache fits in it. The sizes of the recent queue and the ghost
+// queue are changed in the same proportion. Returns the number of evicted items.
+func (c *Cache[K, V]) Resize(n int) (evicted int) {
+	c.setCapacity(n)
+	for c.Len() > n {
+		if _, _, ok := c.Evict(); !ok {
+			break
+		}
+		evicted++
+	}
+	for c.ghost.Len() > c.ghostCap {
+		oldest := c.ghost.Remove(c.ghost.Back()).(K)
+		delete(c.ghosts, oldest)
+	}
+	return evicted
+}
+
+// Clear removes all items from the cache at once, and forgets the keys in the
+// ghost queue.
+func (c *Cache[K, V]) Clear() {
+	c.recent.Init()
+	c.frequent.Init()
+	c.ghost.Init()
+	c.items = make(map[K]*list.Element, c.cap)
+	c.ghosts = make(map[K]*list.Element)
+}
@@ -0,0 +1,144 @@
+package twoqueue_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gekatateam/go-generics-cache/policy/twoqueue"
+)
+
+func TestSet(t *testing.T) {
+	// set capacity is 1
+	cache := twoqueue.NewCache[string, int](twoqueue.WithCapacity(1))
+	cache.Set("foo", 1)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	if got, ok := cache.Get("foo"); got != 1 || !ok {
+		t.Fatalf("invalid value got %d, cachehit %v", got, ok)
+	}
+
+	// if over the cap
+	cache.Set("bar", 2)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok := cache.Get("bar")
+	if bar != 2 || !ok {
+		t.Fatalf("invalid value bar %d, cachehit %v", bar, ok)
+	}
+	if _, ok := cache.Get("foo"); ok {
+		t.Fatalf("invalid eviction foo %v", ok)
+	}
+
+	// valid: if over the cap but same key
+	cache.Set("bar", 100)
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	bar, ok = cache.Get("bar")
+	if bar != 100 || !ok {
+		t.Fatalf("invalid replacing value bar %d, cachehit %v", bar, ok)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := twoqueue.NewCache[string, int](twoqueue.WithCapacity(2))
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Get("bar")
+
+	cache.Delete("foo2")
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length after deleted does not exist key: %d", got)
+	}
+	cache.Delete("foo")
+	cache.Delete("bar")
+	if got := cache.Len(); got != 0 {
+		t.Fatalf("invalid length after deleted: %d", got)
+	}
+	if _, ok := cache.Get("bar"); ok {
+		t.Fatalf("invalid get after deleted %v", ok)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cache := twoqueue.NewCache[string, int]()
+	cache.Set("foo", 1)
+	cache.Set("bar", 2)
+	cache.Set("baz", 3)
+	cache.Get("foo") // promoted
+
+	got := strings.Join(cache.Keys(), ",")
+	if want := "bar,baz,foo"; got != want {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
+
+func TestScanResistance(t *testing.T) {
+	cache := twoqueue.NewCache[int, int](twoqueue.WithCapacity(100))
+	for i := 0; i < 50; i++ {
+		cache.Set(i, i)
+		cache.Get(i)
+	}
+
+	// a single large scan must not evict the established hot set.
+	for i := 1000; i < 11000; i++ {
+		cache.Set(i, i)
+	}
+	if got := cache.Len(); got != 100 {
+		t.Fatalf("invalid length: %d", got)
+	}
+	for i := 0; i < 50; i++ {
+		if _, ok := cache.Peek(i); !ok {
+			t.Fatalf("want the hot item %d to be kept", i)
+		}
+	}
+}
+
+func TestGhost(t *testing.T) {
+	cache := twoqueue.NewCache[string, int](twoqueue.WithCapacity(2), twoqueue.WithGhostRatio(1))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Set("c", 3) // a is evicted and remembered
+
+	cache.Set("a", 1) // a is stored in the frequent queue, b is evicted
+	cache.Set("d", 4) // c is evicted from the recent queue
+	if got := strings.Join(cache.Keys(), ","); got != "d,a" {
+		t.Fatalf("want the reclaimed key to be kept, but got %q", got)
+	}
+}
+
+func TestSetWithEvicted(t *testing.T) {
+	cache := twoqueue.NewCache[string, int](twoqueue.WithCapacity(2))
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if _, _, evicted := cache.SetWithEvicted("b", 20); evicted {
+		t.Fatal("want no eviction when replacing")
+	}
+	key, val, evicted := cache.SetWithEvicted("c", 3)
+	if !evicted || key != "a" || val != 1 {
+		t.Fatalf("want a 1 to be evicted, but got %q %d %v", key, val, evicted)
+	}
+	if got := cache.Len(); got != 2 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	cache := twoqueue.NewCache[string, int]()
+	if _, _, ok := cache.Evict(); ok {
+		t.Fatal("want no eviction from the empty cache")
+	}
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	cache.Get("a")
+	// the recent queue is within its size, so the frequent queue is evicted.
+	key, val, ok := cache.Evict()
+	if !ok || key != "a" || val != 1 {
+		t.Fatalf("want a 1 to be evicted, but got %q %d %v", key, val, ok)
+	}
+	if got := cache.Len(); got != 1 {
+		t.Fatalf("invalid length: %d", got)
+	}
+}
@@ -0,0 +1,59 @@
+package cache
+
+import "time"
+
+// ReadThroughLoader loads the value of key from an external store, and returns
+// how long the value should be cached. If the duration is not positive, the
+// default expiration of the cache is applied.
+type ReadThroughLoader[K comparable, V any] func(key K) (V, time.Duration, error)
+
+// ReadThrough is a read-through cache in front of an external store.
+//
+// Get looks up the cache, and loads the value by the loader on a miss like
+// GetOrLoad, so concurrent misses for the same key are collapsed into a single
+// loader call. Unlike GetOrLoad, the expiration of each value is decided by the
+// loader, so different keys can have different freshness.
+//
+// Errors from the loader are returned to the callers and are not cached,
+// unless the cache is created with WithNegativeTTL and the loader returns
+// ErrNotFound.
+type ReadThrough[K comparable, V any] struct {
+	cache  *Cache[K, V]
+	loader ReadThroughLoader[K, V]
+}
+
+// NewReadThrough creates a new ReadThrough cache which loads values by loader
+// into c. c may be used directly as well, e.g. to Set fresh values.
+func NewReadThrough[K comparable, V any](c *Cache[K, V], loader ReadThroughLoader[K, V]) *ReadThrough[K, V] {
+	return &ReadThrough[K, V]{
+		cache:  c,
+		loader: loader,
+	}
+}
+
+// Get looks up a key's value from the cache, and loads it by the loader and
+// stores it with the returned TTL if it is not found.
+func (r *ReadThrough[K, V]) Get(key K) (V, error) {
+	var ttl time.Duration
+	// the option is applied after the loader returns.
+	withTTL := func(o *itemOptions) {
+		if ttl > 0 {
+			WithExpiration(ttl)(o)
+		}
+	}
+	return r.cache.GetOrLoad(key, func(key K) (V, error) {
+		val, d, err := r.loader(key)
+		ttl = d
+		return val, err
+	}, withTTL)
+}
+
+// Delete deletes the item of key from the cache, so the next Get loads it again.
+func (r *ReadThrough[K, V]) Delete(key K) {
+	r.cache.Delete(key)
+}
+
+// Cache returns the underlying cache.
+func (r *ReadThrough[K, V]) Cache() *Cache[K, V] {
+	return r.cache
+}
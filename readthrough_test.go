@@ -0,0 +1,69 @@
+package cache_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestReadThrough(t *testing.T) {
+	now := time.Now()
+	reset := cache.SetNowFunc(now)
+	defer reset()
+
+	var calls int32
+	release := make(chan struct{})
+	errBackend := errors.New("backend is down")
+	rt := cache.NewReadThrough(cache.New[string, int](), func(key string) (int, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		switch key {
+		case "slow":
+			<-release
+			return 1, time.Minute, nil
+		case "fast":
+			return 2, time.Second, nil
+		}
+		return 0, 0, errBackend
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := rt.Get("slow"); err != nil || got != 1 {
+				t.Errorf("want 1 but got %v %v", got, err)
+			}
+		}()
+	}
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("want the loader to be called once but got %d", got)
+	}
+
+	if got, err := rt.Get("fast"); err != nil || got != 2 {
+		t.Fatalf("want 2 but got %v %v", got, err)
+	}
+	for key, ttl := range map[string]time.Duration{"slow": time.Minute, "fast": time.Second} {
+		if _, exp, _ := rt.Cache().GetWithExpiration(key); !exp.Equal(now.Add(ttl)) {
+			t.Errorf("want %q to expire in %v but got %v", key, ttl, exp)
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := rt.Get("broken"); !errors.Is(err, errBackend) {
+			t.Fatalf("want the loader error but got %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(&calls); got != 4 {
+		t.Fatalf("want errors not to be cached but got %d calls", got)
+	}
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/gekatateam/go-generics-cache/internal/hash"
+)
+
+// Sharded is a thread safe cache which distributes keys across several
+// independent Cache instances to reduce lock contention.
+//
+// Each shard has its own lock, janitor and cache replacement policy, so the
+// capacity option of the policy applies to each shard, not to the whole cache.
+type Sharded[K comparable, V any] struct {
+	shards []*Cache[K, V]
+	hasher func(K) uint64
+}
+
+// WithHasher is an option to specify the hash function which distributes keys across shards of Sharded.
+//
+// Default is FNV-1a over the binary representation of common key types, or over
+// the Go-syntax representation of any other types.
+func WithHasher[K comparable, V any](fn func(K) uint64) Option[K, V] {
+	return func(o *options[K, V]) {
+		if fn == nil {
+			o.invalid("WithHasher: nil hash function")
+		}
+		o.hasher = fn
+	}
+}
+
+// NewSharded creates a new Sharded cache which has the specified number of shards.
+// Each shard is created with the given options like New, except that the trace
+// of WithTraceRecorder is recorded by a single recorder shared by all shards,
+// so that the trace is a single stream.
+//
+// A policy of WithPolicy cannot be shared by shards, so NewSharded panics if
+// it is given with more than one shard.
+func NewSharded[K comparable, V any](shards int, opts ...Option[K, V]) *Sharded[K, V] {
+	return NewShardedContext(context.Background(), shards, opts...)
+}
+
+// NewShardedContext creates a new Sharded cache with context like NewContext.
+// The janitors of all shards will be stopped when the context is cancelled.
+func NewShardedContext[K comparable, V any](ctx context.Context, shards int, opts ...Option[K, V]) *Sharded[K, V] {
+	if shards < 1 {
+		shards = 1
+	}
+	s := &Sharded[K, V]{
+		shards: make([]*Cache[K, V], shards),
+		hasher: hash.Key[K],
+	}
+	var trace *traceRecorder[K]
+	for i := range s.shards {
+		o := newOptions[K, V]()
+		for _, optFunc := range opts {
+			optFunc(o)
+		}
+		if o.custom && shards > 1 {
+			panic("cache: WithPolicy cannot be used with more than one shard")
+		}
+		if o.hasher != nil {
+			s.hasher = o.hasher
+		}
+		// the recorders of the shards would interleave their gob streams.
+		if i == 0 {
+			trace = o.trace
+		}
+		o.trace = trace
+		s.shards[i] = newCache(ctx, o)
+	}
+	return s
+}
+
+// shard returns the shard which the key belongs to.
+func (s *Sharded[K, V]) shard(key K) *Cache[K, V] {
+	return s.shards[s.hasher(key)%uint64(len(s.shards))]
+}
+
+// Get looks up a key's value from the cache.
+func (s *Sharded[K, V]) Get(key K) (value V, ok bool) {
+	return s.shard(key).Get(key)
+}
+
+// Set sets a value to the cache with key. replacing any existing value.
+func (s *Sharded[K, V]) Set(key K, val V, opts ...ItemOption) {
+	s.shard(key).Set(key, val, opts...)
+}
+
+// Delete deletes the item with provided key from the cache.
+func (s *Sharded[K, V]) Delete(key K) {
+	s.shard(key).Delete(key)
+}
+
+// Contains reports whether key is within cache.
+func (s *Sharded[K, V]) Contains(key K) bool {
+	return s.shard(key).Contains(key)
+}
+
+// Keys returns the keys of all shards. the order is relied on algorithms within each shard.
+func (s *Sharded[K, V]) Keys() []K {
+	var keys []K
+	for _, shard := range s.shards {
+		keys = append(keys, shard.Keys()...)
+	}
+	return keys
+}
+
+// List returns the items of all shards.
+func (s *Sharded[K, V]) List() map[K]V {
+	items := make(map[K]V)
+	for _, shard := range s.shards {
+		for key, val := range shard.List() {
+			items[key] = val
+		}
+	}
+	return items
+}
+
+// Len returns the number of items in all shards.
+func (s *Sharded[K, V]) Len() int {
+	n := 0
+	for _, shard := range s.shards {
+		n += shard.Len()
+	}
+	return n
+}
+
+// Flush deletes all items from all shards.
+func (s *Sharded[K, V]) Flush() {
+	for _, shard := range s.shards {
+		shard.Flush()
+	}
+}
+
+// DeleteExpired deletes all expired items from all shards.
+func (s *Sharded[K, V]) DeleteExpired() {
+	for _, shard := range s.shards {
+		shard.DeleteExpired()
+	}
+}
@@ -0,0 +1,142 @@
+package cache_test
+
+import (
+	"bytes"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+)
+
+func TestSharded(t *testing.T) {
+	c := cache.NewSharded[string, int](4)
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	for i := 0; i < 100; i++ {
+		if got, ok := c.Get(strconv.Itoa(i)); !ok || got != i {
+			t.Fatalf("want %d true but got %d %v", i, got, ok)
+		}
+	}
+	if got := c.Len(); got != 100 {
+		t.Fatalf("want 100 but got %d", got)
+	}
+	if got := len(c.List()); got != 100 {
+		t.Fatalf("want 100 items but got %d", got)
+	}
+	keys := c.Keys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := strconv.Atoi(keys[i])
+		b, _ := strconv.Atoi(keys[j])
+		return a < b
+	})
+	for i, key := range keys {
+		if key != strconv.Itoa(i) {
+			t.Fatalf("want key %d but got %s", i, key)
+		}
+	}
+
+	c.Delete("1")
+	if c.Contains("1") {
+		t.Fatal("want deleted")
+	}
+	c.Flush()
+	if got := c.Len(); got != 0 {
+		t.Fatalf("want 0 after flush but got %d", got)
+	}
+}
+
+func TestShardedWithHasher(t *testing.T) {
+	// all keys go to a single shard, so the capacity of the shard bounds them.
+	c := cache.NewSharded(4,
+		cache.AsLRU[int, int](lru.WithCapacity(2)),
+		cache.WithHasher[int, int](func(int) uint64 { return 0 }),
+	)
+	c.Set(1, 1)
+	c.Set(2, 2)
+	c.Set(3, 3)
+	if got := c.Len(); got != 2 {
+		t.Fatalf("want 2 but got %d", got)
+	}
+}
+
+func benchmarkParallel(b *testing.B, get func(int) (int, bool), set func(int, int)) {
+	for i := 0; i < 1000; i++ {
+		set(i, i)
+	}
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		m := rand.New(rand.NewSource(rand.Int63()))
+		for pb.Next() {
+			key := m.Intn(1000)
+			if m.Intn(10) == 0 {
+				set(key, key)
+			} else {
+				get(key)
+			}
+		}
+	})
+}
+
+func BenchmarkCacheParallel(b *testing.B) {
+	c := cache.New(cache.AsLRU[int, int](lru.WithCapacity(1000)))
+	benchmarkParallel(b, c.Get, func(k, v int) { c.Set(k, v) })
+}
+
+func BenchmarkShardedParallel(b *testing.B) {
+	c := cache.NewSharded(32, cache.AsLRU[int, int](lru.WithCapacity(1000)))
+	benchmarkParallel(b, c.Get, func(k, v int) { c.Set(k, v) })
+}
+
+func TestShardedTraceRecorder(t *testing.T) {
+	var buf bytes.Buffer
+	c := cache.NewSharded(4, cache.WithTraceRecorder[string, int](&buf))
+	for i := 0; i < 100; i++ {
+		c.Set(strconv.Itoa(i), i)
+	}
+	for i := 0; i < 110; i++ {
+		c.Get(strconv.Itoa(i))
+	}
+
+	got, err := cache.ReplayTrace(&buf, func() *cache.Cache[string, int] {
+		return cache.New[string, int]()
+	})
+	if err != nil {
+		t.Fatalf("want a single valid trace but got %v", err)
+	}
+	if want := (cache.Stats{Hits: 100, Misses: 10}); got != want {
+		t.Fatalf("want %+v but got %+v", want, got)
+	}
+}
+
+func TestShardedWithPolicy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("want panic for a policy shared by shards")
+		}
+	}()
+	cache.NewSharded(2, cache.WithPolicy[string, int](lru.NewCache[string, *cache.Item[string, int]]()))
+}
+
+func TestShardedJitterSource(t *testing.T) {
+	c := cache.NewSharded(4, cache.WithJitterSource[int, int](rand.NewSource(1)))
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				c.Set(g*100+i, i, cache.WithExpirationJitter(time.Hour, time.Minute))
+			}
+		}(g)
+	}
+	wg.Wait()
+	if got := c.Len(); got != 400 {
+		t.Fatalf("want 400 items but got %d", got)
+	}
+}
@@ -0,0 +1,42 @@
+package cache
+
+// WithMaxBytes is an option to bound the cache by the total size of items
+// instead of the number of them. sizer measures the size of each item when
+// it is set.
+//
+// After each Set, the cache evicts items in the order of the cache replacement
+// policy until the total size is back under limit. This requires the policy
+// to implement the Evict method, which all of the policies in this module do.
+func WithMaxBytes[K comparable, V any](limit int64, sizer func(K, V) int64) Option[K, V] {
+	return func(o *options[K, V]) {
+		if limit <= 0 || sizer == nil {
+			o.invalid("WithMaxBytes: invalid limit %d or nil sizer", limit)
+		}
+		o.maxBytes = limit
+		o.sizer = sizer
+	}
+}
+
+// Bytes returns the total size of items measured by the sizer of WithMaxBytes.
+// It is always 0 unless the cache is created with WithMaxBytes.
+func (c *Cache[K, V]) Bytes() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.bytes
+}
+
+// shrink evicts items until the total size is under the limit.
+// The caller must hold the write lock.
+func (c *Cache[K, V]) shrink() {
+	ec, ok := c.cache.(interface {
+		Evict() (key K, val *Item[K, V], ok bool)
+	})
+	if !ok {
+		return
+	}
+	for c.maxBytes > 0 && c.bytes > c.maxBytes {
+		if _, ok := c.evictNext(ec); !ok {
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package cache_test
+
+import (
+	"strings"
+	"testing"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestMaxBytes(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.AsLRU[string, []byte](),
+		cache.WithMaxBytes(10, func(_ string, v []byte) int64 { return int64(len(v)) }),
+		cache.WithEvictionCallback(func(k string, _ []byte) { evicted = append(evicted, k) }),
+	)
+	c.Set("a", make([]byte, 4))
+	c.Set("b", make([]byte, 4))
+	if got := c.Bytes(); got != 8 {
+		t.Fatalf("want 8 bytes but got %d", got)
+	}
+
+	c.Get("a")
+	c.Set("c", make([]byte, 4))
+	if got := c.Bytes(); got != 8 {
+		t.Fatalf("want 8 bytes but got %d", got)
+	}
+	if got := strings.Join(evicted, ","); got != "b" {
+		t.Fatalf("want b to be evicted but got %q", got)
+	}
+
+	// replacing an item accounts the difference of the sizes.
+	c.Set("a", make([]byte, 6))
+	if got := c.Bytes(); got != 10 {
+		t.Fatalf("want 10 bytes but got %d", got)
+	}
+
+	c.Delete("c")
+	if got := c.Bytes(); got != 6 {
+		t.Fatalf("want 6 bytes after deleting but got %d", got)
+	}
+	c.Flush()
+	if got := c.Bytes(); got != 0 {
+		t.Fatalf("want 0 bytes after flush but got %d", got)
+	}
+}
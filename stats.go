@@ -0,0 +1,106 @@
+package cache
+
+import "sync/atomic"
+
+// Stats is statistics of cache accesses.
+//
+// The removals of items are broken down by cause into CapacityEvictions,
+// Expirations and ManualDeletes. Many capacity evictions suggest growing the
+// capacity, and many expirations suggest lengthening the expiration.
+type Stats struct {
+	// Hits is the number of lookups which found the key.
+	Hits uint64
+	// Misses is the number of lookups which did not find the key.
+	Misses uint64
+	// CapacityEvictions is the number of items evicted by the cache replacement
+	// policy due to the capacity, including by Resize, EvictOldest and
+	// WithMaxBytes.
+	CapacityEvictions uint64
+	// Evictions is the same as CapacityEvictions.
+	//
+	// Deprecated: Use CapacityEvictions.
+	Evictions uint64
+	// Expirations is the number of expired items deleted by the janitor.
+	Expirations uint64
+	// ManualDeletes is the number of items deleted explicitly by Delete and
+	// the other deleters, Clear and Flush.
+	ManualDeletes uint64
+}
+
+// HitRatio returns the ratio of hits to all lookups.
+// Returns 0 if there have been no lookups.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// statsCounter collects Stats with atomic counters, so it is updated without
+// holding the lock of the cache. All methods are no-op on a nil counter.
+type statsCounter struct {
+	hits        uint64
+	misses      uint64
+	evictions   uint64
+	expirations uint64
+	deletes     uint64
+}
+
+func (s *statsCounter) hit() {
+	if s != nil {
+		atomic.AddUint64(&s.hits, 1)
+	}
+}
+
+func (s *statsCounter) miss() {
+	if s != nil {
+		atomic.AddUint64(&s.misses, 1)
+	}
+}
+
+func (s *statsCounter) evicted() {
+	if s != nil {
+		atomic.AddUint64(&s.evictions, 1)
+	}
+}
+
+func (s *statsCounter) expired() {
+	if s != nil {
+		atomic.AddUint64(&s.expirations, 1)
+	}
+}
+
+func (s *statsCounter) deleted(n int) {
+	if s != nil {
+		atomic.AddUint64(&s.deletes, uint64(n))
+	}
+}
+
+func (s *statsCounter) snapshot() Stats {
+	if s == nil {
+		return Stats{}
+	}
+	evictions := atomic.LoadUint64(&s.evictions)
+	return Stats{
+		Hits:              atomic.LoadUint64(&s.hits),
+		Misses:            atomic.LoadUint64(&s.misses),
+		CapacityEvictions: evictions,
+		Evictions:         evictions,
+		Expirations:       atomic.LoadUint64(&s.expirations),
+		ManualDeletes:     atomic.LoadUint64(&s.deletes),
+	}
+}
+
+// WithStats is an option to collect statistics of the cache, which can be read by Cache.Stats.
+func WithStats[K comparable, V any]() Option[K, V] {
+	return func(o *options[K, V]) {
+		o.stats = true
+	}
+}
+
+// Stats returns the statistics of the cache.
+// All counters are zero unless the cache is created with WithStats.
+func (c *Cache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
@@ -0,0 +1,60 @@
+package cache
+
+// WithTags is an option to associate the item with tags, so that the items
+// sharing a tag can be deleted at once by InvalidateTag. Tags are added to the
+// ones given by preceding options.
+func WithTags(tags ...string) ItemOption {
+	return func(o *itemOptions) {
+		o.tags = append(o.tags[:len(o.tags):len(o.tags)], tags...)
+	}
+}
+
+// InvalidateTag deletes all items which are tagged with tag by WithTags, and
+// returns how many items are deleted. The eviction callback is called for each
+// of them as Delete does.
+//
+// The items are found by an index of tags, which is kept up to date as items
+// are set, deleted, expired and evicted, so it does not scan the whole cache.
+func (c *Cache[K, V]) InvalidateTag(tag string) int {
+	c.mu.Lock()
+	defer c.unlock()
+	if c.frozen {
+		return 0
+	}
+	keys := make([]K, 0, len(c.tags[tag]))
+	for key := range c.tags[tag] {
+		keys = append(keys, key)
+	}
+	for _, key := range keys {
+		c.delete(key)
+	}
+	return len(keys)
+}
+
+// tag adds the key of item to the index of its tags. The caller must hold
+// the write lock.
+func (c *Cache[K, V]) tag(item *Item[K, V]) {
+	for _, tag := range item.tags {
+		keys, ok := c.tags[tag]
+		if !ok {
+			if c.tags == nil {
+				c.tags = make(map[string]map[K]struct{})
+			}
+			keys = make(map[K]struct{})
+			c.tags[tag] = keys
+		}
+		keys[item.Key] = struct{}{}
+	}
+}
+
+// untag removes the key of item from the index of its tags. The caller must
+// hold the write lock.
+func (c *Cache[K, V]) untag(item *Item[K, V]) {
+	for _, tag := range item.tags {
+		keys := c.tags[tag]
+		delete(keys, item.Key)
+		if len(keys) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package cache_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+)
+
+func TestInvalidateTag(t *testing.T) {
+	var evicted []string
+	c := cache.New(
+		cache.WithEvictionCallback(func(key string, _ int) {
+			evicted = append(evicted, key)
+		}),
+	)
+	c.Set("profile", 1, cache.WithTags("user:42"))
+	c.Set("posts", 2, cache.WithTags("user:42", "posts"))
+	c.Set("other", 3, cache.WithTags("user:7"))
+	c.Set("plain", 4)
+
+	if got := c.InvalidateTag("user:42"); got != 2 {
+		t.Fatalf("want 2 invalidated items but got %d", got)
+	}
+	keys := c.Keys()
+	sort.Strings(keys)
+	if got := strings.Join(keys, ","); got != "other,plain" {
+		t.Fatalf("want other,plain but got %q", got)
+	}
+	if len(evicted) != 2 {
+		t.Fatalf("want the eviction callback for 2 items but got %v", evicted)
+	}
+	if got := c.InvalidateTag("posts"); got != 0 {
+		t.Fatalf("want the deleted item to be untagged but got %d", got)
+	}
+	if got := c.InvalidateTag("unknown"); got != 0 {
+		t.Fatalf("want 0 but got %d", got)
+	}
+}
+
+func TestInvalidateTagConsistency(t *testing.T) {
+	c := cache.New(cache.AsLRU[string, int](lru.WithCapacity(2)))
+
+	// replacing the item replaces its tags.
+	c.Set("a", 1, cache.WithTags("x"))
+	c.Set("a", 1, cache.WithTags("y"))
+	if got := c.InvalidateTag("x"); got != 0 {
+		t.Fatalf("want the replaced tag to be removed but got %d", got)
+	}
+
+	// eviction and expiration remove the item from the index.
+	c.Set("b", 2, cache.WithTags("y"), cache.WithExpiration(-time.Second))
+	c.DeleteExpired()
+	c.Set("c", 3, cache.WithTags("y"))
+	c.Set("d", 4, cache.WithTags("y"))
+	if got := c.InvalidateTag("y"); got != 2 {
+		t.Fatalf("want 2 invalidated items but got %d", got)
+	}
+
+	// updates of the value keep the tags.
+	nc := cache.NewNumber[string, int]()
+	nc.Set("n", 1, cache.WithTags("z"))
+	nc.Increment("n", 1)
+	if got := nc.InvalidateTag("z"); got != 1 || nc.Contains("n") {
+		t.Fatalf("want the incremented item to keep its tag but got %d", got)
+	}
+}
@@ -0,0 +1,96 @@
+package cache
+
+// Tiered is a two-level cache which stacks a small fast cache (L1) in front of
+// a larger one (L2).
+//
+// Get looks up L1 first, and falls through to L2 on a miss. An item found in
+// L2 is promoted to L1 with the same expiration, so L1 holds the hot subset of
+// L2. Delete removes items from both levels. How Set writes items is decided
+// by the TierWritePolicy.
+type Tiered[K comparable, V any] struct {
+	l1, l2 *Cache[K, V]
+	write  TierWritePolicy
+}
+
+// TierWritePolicy is how Tiered writes items to the levels.
+type TierWritePolicy int
+
+const (
+	// WriteThrough writes items to both L1 and L2. This is the default.
+	WriteThrough TierWritePolicy = iota
+	// WriteAround writes items only to L2, and deletes the stale items from
+	// L1. Items get into L1 only when they are read, so writes which are
+	// never read do not pollute L1.
+	WriteAround
+)
+
+// TieredOption is an option for Tiered cache.
+type TieredOption func(*tieredOptions)
+
+type tieredOptions struct {
+	write TierWritePolicy
+}
+
+// WithTierWritePolicy is an option to specify how Tiered writes items.
+//
+// Default is WriteThrough.
+func WithTierWritePolicy(p TierWritePolicy) TieredOption {
+	return func(o *tieredOptions) {
+		o.write = p
+	}
+}
+
+// NewTiered creates a new Tiered cache of l1 and l2. The caches keep their own
+// options such as the cache replacement policy and the janitor.
+func NewTiered[K comparable, V any](l1, l2 *Cache[K, V], opts ...TieredOption) *Tiered[K, V] {
+	o := new(tieredOptions)
+	for _, optFunc := range opts {
+		optFunc(o)
+	}
+	return &Tiered[K, V]{
+		l1:    l1,
+		l2:    l2,
+		write: o.write,
+	}
+}
+
+// Get looks up a key's value from L1, and then from L2. The item found in L2
+// is promoted to L1.
+func (t *Tiered[K, V]) Get(key K) (value V, ok bool) {
+	if value, ok = t.l1.Get(key); ok {
+		return value, true
+	}
+	value, exp, ok := t.l2.GetWithExpiration(key)
+	if !ok {
+		return value, false
+	}
+	t.l1.Set(key, value, withExpirationTime(exp))
+	return value, true
+}
+
+// Set sets a value to the cache with key according to the TierWritePolicy.
+func (t *Tiered[K, V]) Set(key K, val V, opts ...ItemOption) {
+	t.l2.Set(key, val, opts...)
+	if t.write == WriteAround {
+		t.l1.Delete(key)
+		return
+	}
+	t.l1.Set(key, val, opts...)
+}
+
+// Delete deletes the item with provided key from both L1 and L2.
+func (t *Tiered[K, V]) Delete(key K) {
+	t.l1.Delete(key)
+	t.l2.Delete(key)
+}
+
+// Contains reports whether key is within L1 or L2.
+func (t *Tiered[K, V]) Contains(key K) bool {
+	return t.l1.Contains(key) || t.l2.Contains(key)
+}
+
+// Flush deletes all items from both L1 and L2.
+func (t *Tiered[K, V]) Flush() {
+	t.l1.Flush()
+	t.l2.Flush()
+}
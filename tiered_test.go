@@ -0,0 +1,57 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/gekatateam/go-generics-cache/policy/fifo"
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+)
+
+func TestTiered(t *testing.T) {
+	l1 := cache.New(cache.AsLRU[string, int](lru.WithCapacity(1)))
+	l2 := cache.New(cache.AsFIFO[string, int](fifo.WithCapacity(10)))
+	c := cache.NewTiered(l1, l2)
+
+	c.Set("a", 1, cache.WithExpiration(time.Minute))
+	c.Set("b", 2)
+	if l1.Contains("a") || !l1.Contains("b") || !l2.Contains("a") || !l2.Contains("b") {
+		t.Fatal("want items to be written through both levels")
+	}
+
+	// a falls through to L2, and is promoted with its expiration.
+	if got, ok := c.Get("a"); !ok || got != 1 {
+		t.Fatalf("want 1 true but got %v %v", got, ok)
+	}
+	if d, ok := l1.TTL("a"); !ok || d > time.Minute || d <= 0 {
+		t.Fatalf("want a to be promoted to L1 with the expiration, but got %v %v", d, ok)
+	}
+
+	c.Delete("a")
+	if c.Contains("a") {
+		t.Fatal("want a to be deleted from both levels")
+	}
+	if _, ok := c.Get("c"); ok {
+		t.Fatal("want c not to be found")
+	}
+}
+
+func TestTieredWriteAround(t *testing.T) {
+	l1 := cache.New[string, int]()
+	l2 := cache.New[string, int]()
+	c := cache.NewTiered(l1, l2, cache.WithTierWritePolicy(cache.WriteAround))
+
+	c.Set("a", 1)
+	if l1.Contains("a") || !l2.Contains("a") {
+		t.Fatal("want a to be written only to L2")
+	}
+	c.Get("a")
+	c.Set("a", 2)
+	if l1.Contains("a") {
+		t.Fatal("want the stale item to be deleted from L1")
+	}
+	if got, _ := c.Get("a"); got != 2 {
+		t.Fatalf("want 2 but got %v", got)
+	}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"encoding/gob"
+	"errors"
+	"io"
+	"sync"
+)
+
+// traceOp is a kind of traced cache operation.
+type traceOp uint8
+
+const (
+	traceGet traceOp = iota + 1
+	traceSet
+	traceDelete
+)
+
+// traceRecord is a record of the access trace.
+type traceRecord[K comparable] struct {
+	Op   traceOp
+	Key  K
+	Time int64 // unix nano
+}
+
+// traceRecorder writes the access trace as a gob stream.
+type traceRecorder[K comparable] struct {
+	mu  sync.Mutex
+	enc *gob.Encoder
+	err error
+}
+
+func newTraceRecorder[K comparable](w io.Writer) *traceRecorder[K] {
+	return &traceRecorder[K]{
+		enc: gob.NewEncoder(w),
+	}
+}
+
+// record writes the operation. Once writing failed, the recorder stops recording.
+func (r *traceRecorder[K]) record(op traceOp, key K) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.enc.Encode(traceRecord[K]{
+		Op:   op,
+		Key:  key,
+		Time: nowFunc().UnixNano(),
+	})
+}
+
+// WithTraceRecorder is an option to record a trace of Get, Set and Delete
+// operations to w. Each record has the key, the operation and the timestamp,
+// and the trace is encoded as a gob stream, so K must be encodable by gob.
+//
+// The recorded trace can be replayed by ReplayTrace to compare the cache
+// replacement policies with actual traffic. If writing to w fails, the
+// recording stops silently and the cache keeps working.
+func WithTraceRecorder[K comparable, V any](w io.Writer) Option[K, V] {
+	return func(o *options[K, V]) {
+		o.trace = newTraceRecorder[K](w)
+	}
+}
+
+// ReplayTrace feeds the access trace recorded by WithTraceRecorder through a
+// cache created by newCache, and reports the hits and misses of Get operations.
+// Set operations store the zero value of V.
+func ReplayTrace[K comparable, V any](r io.Reader, newCache func() *Cache[K, V]) (Stats, error) {
+	var stats Stats
+	c := newCache()
+	dec := gob.NewDecoder(r)
+	var zero V
+	for {
+		var rec traceRecord[K]
+		if err := dec.Decode(&rec); err != nil {
+			if errors.Is(err, io.EOF) {
+				return stats, nil
+			}
+			return stats, err
+		}
+		switch rec.Op {
+		case traceGet:
+			if _, ok := c.Get(rec.Key); ok {
+				stats.Hits++
+			} else {
+				stats.Misses++
+			}
+		case traceSet:
+			c.Set(rec.Key, zero)
+		case traceDelete:
+			c.Delete(rec.Key)
+		}
+	}
+}
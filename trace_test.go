@@ -0,0 +1,73 @@
+package cache_test
+
+import (
+	"bytes"
+	"testing"
+
+	cache "github.com/gekatateam/go-generics-cache"
+	"github.com/gekatateam/go-generics-cache/policy/lfu"
+	"github.com/gekatateam/go-generics-cache/policy/lru"
+)
+
+func TestReplayTrace(t *testing.T) {
+	var buf bytes.Buffer
+	c := cache.New(cache.WithTraceRecorder[string, int](&buf))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Set("c", 3)
+	c.Get("a")
+	c.Delete("b")
+	c.Get("b")
+
+	trace := buf.Bytes()
+	cases := []struct {
+		name     string
+		newCache func() *cache.Cache[string, int]
+		want     cache.Stats
+	}{
+		{
+			name:     "simple",
+			newCache: func() *cache.Cache[string, int] { return cache.New[string, int]() },
+			want:     cache.Stats{Hits: 4, Misses: 1},
+		},
+		{
+			name: "LRU",
+			newCache: func() *cache.Cache[string, int] {
+				return cache.New(cache.AsLRU[string, int](lru.WithCapacity(2)))
+			},
+			// a is evicted by c
+			want: cache.Stats{Hits: 3, Misses: 2},
+		},
+		{
+			name: "LFU",
+			newCache: func() *cache.Cache[string, int] {
+				return cache.New(cache.AsLFU[string, int](lfu.WithCapacity(2)))
+			},
+			// b is evicted by c
+			want: cache.Stats{Hits: 4, Misses: 1},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := cache.ReplayTrace(bytes.NewReader(trace), tc.newCache)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("want %+v but got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStatsHitRatio(t *testing.T) {
+	if got := (cache.Stats{}).HitRatio(); got != 0 {
+		t.Errorf("want 0 but got %v", got)
+	}
+	if got := (cache.Stats{Hits: 3, Misses: 1}).HitRatio(); got != 0.75 {
+		t.Errorf("want 0.75 but got %v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// WarmError is returned by Warm when loading some keys failed.
+type WarmError[K comparable] struct {
+	// Errors holds the error of each key which failed to load.
+	Errors map[K]error
+}
+
+// Error implements error interface.
+func (e *WarmError[K]) Error() string {
+	for key, err := range e.Errors {
+		if len(e.Errors) == 1 {
+			return fmt.Sprintf("cache: failed to warm key %v: %v", key, err)
+		}
+		return fmt.Sprintf("cache: failed to warm %d keys, e.g. key %v: %v", len(e.Errors), key, err)
+	}
+	return "cache: failed to warm"
+}
+
+// Unwrap returns the errors of all failed keys.
+func (e *WarmError[K]) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Warm pre-populates the cache by loading keys with the loader concurrently.
+//
+// At most workers loader calls run at the same time. Each loaded value is
+// stored with the TTL returned by the loader. If the TTL is not positive, the
+// default expiration of the cache is applied, and the item never expires if
+// there is none. Keys which failed to load are not stored, and a
+// *WarmError[K] holding their errors is returned.
+func (c *Cache[K, V]) Warm(keys []K, loader func(K) (V, time.Duration, error), workers int) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs map[K]error
+	)
+	queue := make(chan K)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				epoch := c.Epoch()
+				val, ttl, err := loader(key)
+				if err != nil {
+					mu.Lock()
+					if errs == nil {
+						errs = make(map[K]error)
+					}
+					errs[key] = err
+					mu.Unlock()
+					continue
+				}
+				opts := []ItemOption{WithEpoch(epoch)}
+				if ttl > 0 {
+					opts = append(opts, WithExpiration(ttl))
+				}
+				c.Set(key, val, opts...)
+			}
+		}()
+	}
+	for _, key := range keys {
+		queue <- key
+	}
+	close(queue)
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return &WarmError[K]{Errors: errs}
+	}
+	return nil
+}
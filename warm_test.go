@@ -0,0 +1,58 @@
+package cache_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestWarm(t *testing.T) {
+	c := cache.New[int, int]()
+	errOdd := errors.New("odd")
+
+	var running, maxRunning int64
+	loader := func(key int) (int, time.Duration, error) {
+		n := atomic.AddInt64(&running, 1)
+		defer atomic.AddInt64(&running, -1)
+		for {
+			m := atomic.LoadInt64(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt64(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		if key%2 == 1 {
+			return 0, 0, errOdd
+		}
+		return key * 10, time.Minute, nil
+	}
+
+	keys := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	err := c.Warm(keys, loader, 3)
+
+	var werr *cache.WarmError[int]
+	if !errors.As(err, &werr) {
+		t.Fatalf("want *WarmError but got %v", err)
+	}
+	if len(werr.Errors) != 4 || werr.Errors[1] != errOdd {
+		t.Fatalf("want errors of odd keys but got %v", werr.Errors)
+	}
+	for _, key := range []int{0, 2, 4, 6} {
+		if got, ok := c.Get(key); !ok || got != key*10 {
+			t.Errorf("want %d true but got %d %v", key*10, got, ok)
+		}
+	}
+	if c.Contains(1) {
+		t.Error("want failed keys not to be stored")
+	}
+	if got := atomic.LoadInt64(&maxRunning); got > 3 {
+		t.Errorf("want at most 3 concurrent loads but got %d", got)
+	}
+
+	if err := c.Warm([]int{8}, loader, 0); err != nil {
+		t.Fatalf("want nil but got %v", err)
+	}
+}
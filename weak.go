@@ -0,0 +1,30 @@
+//go:build go1.24
+
+package cache
+
+import "weak"
+
+// WithWeakValues is an option to hold the values of the cache weakly by using
+// weak pointers. It is available with Go 1.24 or later.
+//
+// A value is kept only as long as it is strongly referenced from outside of
+// the cache. Once it has been collected by the GC, Get returns false for the
+// key and the item is deleted by the janitor. Note that the GC does not keep
+// weakly referenced values under low memory pressure, so this is a best-effort
+// cache which never prevents the GC from reclaiming memory.
+func WithWeakValues[K comparable, T any]() Option[K, *T] {
+	return func(o *options[K, *T]) {
+		o.weakRef = makeWeakRef[T]
+	}
+}
+
+func makeWeakRef[T any](v *T) func() (*T, bool) {
+	if v == nil {
+		return func() (*T, bool) { return nil, true }
+	}
+	wp := weak.Make(v)
+	return func() (*T, bool) {
+		p := wp.Value()
+		return p, p != nil
+	}
+}
@@ -0,0 +1,40 @@
+//go:build go1.24
+
+package cache_test
+
+import (
+	"runtime"
+	"testing"
+
+	cache "github.com/gekatateam/go-generics-cache"
+)
+
+func TestWithWeakValues(t *testing.T) {
+	type large struct {
+		buf [1 << 16]byte
+	}
+	c := cache.New(cache.WithWeakValues[string, large]())
+
+	v := &large{}
+	c.Set("a", v)
+	c.Set("b", &large{})
+
+	if got, ok := c.Get("a"); !ok || got != v {
+		t.Fatalf("want the strongly referenced value, but got %p %v", got, ok)
+	}
+
+	runtime.GC()
+
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("want the value to be kept while it is referenced")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("want the value to be collected")
+	}
+	runtime.KeepAlive(v)
+
+	c.DeleteExpired()
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "a" {
+		t.Fatalf("want [a] but got %v", keys)
+	}
+}